@@ -2,8 +2,11 @@
 package main
 
 import (
+	"taskflow/internal/auth"
 	"taskflow/internal/config"
 	"taskflow/internal/email"
+	"taskflow/internal/outbox"
+	"taskflow/internal/repository"
 	"taskflow/internal/server"
 
 	prettyprint "taskflow/pkg/pretty_print"
@@ -16,15 +19,33 @@ func main() {
 		DebugMode: cfg.Debug,
 	})
 
+	// Ключи подписи JWT (в продакшене без нормального ключа не стартуем)
+	if err := auth.Init(cfg.Auth, cfg.IsProd()); err != nil {
+		prettyprint.Fatal("Invalid JWT configuration: %v", err)
+	}
+	if err := auth.InitPasswordHashing(cfg.Password.Hash); err != nil {
+		prettyprint.Fatal("Invalid password hashing configuration: %v", err)
+	}
+
+	// Транспорт писем: Resend, SMTP, файлы .eml или лог (в продакшене лог не годится)
+	sender, err := email.NewSender(cfg.Email, cfg.IsProd())
+	if err != nil {
+		prettyprint.Fatal("Invalid email configuration: %v", err)
+	}
+
+	// Письма сначала сохраняются в очереди, отправляют их фоновые обработчики сервера
+	mailQueue := outbox.NewQueue(repository.NewOutboxRepository(), sender, cfg.Email.Outbox)
+
 	// Создаём email сервис
 	emailService := email.NewService(
-		cfg.Email.ResendAPIKey,
+		mailQueue,
 		cfg.Email.FromEmail,
 		cfg.Email.TestEmail,
+		cfg.PublicURL,
 	)
 
 	// Передаём его в сервер
-	srv := server.New(cfg, emailService)
+	srv := server.New(cfg, emailService, mailQueue)
 
 	if err := srv.Setup(); err != nil {
 		prettyprint.Fatal("Failed to setup server: %v", err)
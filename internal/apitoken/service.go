@@ -0,0 +1,127 @@
+// internal/apitoken/service.go
+package apitoken
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"taskflow/internal/auth"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// Prefix отличает персональный токен от JWT в заголовке Authorization
+const Prefix = "tf_pat_"
+
+// lastUsedInterval как часто обновлять last_used_at
+const lastUsedInterval = time.Minute
+
+var (
+	ErrInvalidToken = errors.New("invalid api token")
+	ErrUnknownScope = errors.New("unknown scope")
+)
+
+// Service выпуск и проверка персональных токенов
+type Service struct {
+	repo *repository.APITokenRepository
+}
+
+func NewService(repo *repository.APITokenRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// IsAPIToken похоже ли значение на персональный токен
+func IsAPIToken(raw string) bool {
+	return strings.HasPrefix(raw, Prefix)
+}
+
+// Create выпускает токен. Возвращает сам токен (показать один раз) и запись.
+func (s *Service) Create(userID uint, name string, scopes []string, ttl time.Duration) (string, *models.APIToken, error) {
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	secret, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	raw := Prefix + secret
+
+	token := &models.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    raw[:len(Prefix)+4],
+		TokenHash: auth.HashToken(raw),
+		Scopes:    strings.Join(scopes, " "),
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.Create(token); err != nil {
+		return "", nil, err
+	}
+	return raw, token, nil
+}
+
+// Authenticate проверяет токен из заголовка
+func (s *Service) Authenticate(raw string) (*models.APIToken, error) {
+	token, err := s.repo.GetByHash(auth.HashToken(raw))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	if !token.Active(now) {
+		return nil, ErrInvalidToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedInterval {
+		if err := s.repo.TouchLastUsed(token.ID, now); err != nil {
+			prettyprint.Warn("Failed to update api token %d: %v", token.ID, err)
+		}
+	}
+	return token, nil
+}
+
+// List токены пользователя
+func (s *Service) List(userID uint) ([]models.APIToken, error) {
+	return s.repo.ListByUser(userID)
+}
+
+// Revoke отзывает токен (false - не найден)
+func (s *Service) Revoke(userID, id uint) (bool, error) {
+	return s.repo.Revoke(userID, id)
+}
+
+// RevokeAll отзывает все токены пользователя (например, после сброса пароля)
+func (s *Service) RevokeAll(userID uint) error {
+	return s.repo.RevokeAllForUser(userID)
+}
+
+// normalizeScopes проверяет права и убирает повторы
+func normalizeScopes(scopes []string) ([]string, error) {
+	known := make(map[string]bool, len(models.APIScopes))
+	for _, scope := range models.APIScopes {
+		known[scope] = true
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !known[scope] {
+			return nil, ErrUnknownScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+// internal/audit/retention.go
+package audit
+
+import (
+	"context"
+	"time"
+
+	"taskflow/internal/repository"
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// Retention удаляет из журнала аудита события старше срока хранения (AUDIT_RETENTION)
+type Retention struct {
+	repo   *repository.AuditRepository
+	period time.Duration
+}
+
+func NewRetention(repo *repository.AuditRepository, period time.Duration) *Retention {
+	return &Retention{repo: repo, period: period}
+}
+
+// Run чистит журнал при запуске и затем раз в час
+func (r *Retention) Run(ctx context.Context) {
+	r.purge(time.Now())
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.purge(now)
+		}
+	}
+}
+
+func (r *Retention) purge(now time.Time) {
+	deleted, err := r.repo.DeleteBefore(now.Add(-r.period))
+	if err != nil {
+		prettyprint.Error("Failed to purge audit events: %v", err)
+		return
+	}
+	if deleted > 0 {
+		prettyprint.Info("Purged %d audit events older than %s", deleted, r.period)
+	}
+}
@@ -6,34 +6,137 @@ import (
     "github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("your-secret-key-here") // В .env!
-
 type Claims struct {
-    UserID uint   `json:"user_id"`
-    Email  string `json:"email"`
+    UserID    uint   `json:"user_id"`
+    Email     string `json:"email"`
+    SessionID string `json:"sid"`
+    Purpose   string `json:"pur,omitempty"` // пусто у access-токена, "2fa" у токена второго шага входа
+    // Impersonator ID администратора, вошедшего от имени пользователя (0 - обычный вход)
+    Impersonator uint `json:"imp,omitempty"`
     jwt.RegisteredClaims
 }
 
-// Генерация токена
-func GenerateToken(userID uint, email string) (string, error) {
+// PurposeTwoFactor токен между вводом пароля и вводом кода 2FA
+const PurposeTwoFactor = "2fa"
+
+// challengeTTL сколько живёт токен второго шага входа
+const challengeTTL = 5 * time.Minute
+
+// Генерация токена (подписывается активным ключом, kid - в заголовке).
+// sessionID - серверная сессия, jti - уникальный ID токена для точечного отзыва.
+func GenerateToken(userID uint, email, sessionID string) (string, error) {
+    return generateAccessToken(userID, email, sessionID, 0)
+}
+
+// GenerateImpersonationToken access-токен администратора adminID, действующего от имени пользователя
+func GenerateImpersonationToken(userID uint, email, sessionID string, adminID uint) (string, error) {
+    return generateAccessToken(userID, email, sessionID, adminID)
+}
+
+func generateAccessToken(userID uint, email, sessionID string, impersonator uint) (string, error) {
+    if keys == nil {
+        return "", errors.New("auth keys are not initialized")
+    }
+
+    jti, err := NewID()
+    if err != nil {
+        return "", err
+    }
+
     claims := Claims{
-        UserID: userID,
-        Email:  email,
+        UserID:    userID,
+        Email:     email,
+        SessionID: sessionID,
+        Impersonator: impersonator,
         RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+            ID:        jti,
+            Issuer:    keys.issuer,
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(keys.accessTTL)),
             IssuedAt:  jwt.NewNumericDate(time.Now()),
         },
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString(jwtSecret)
+    token := jwt.NewWithClaims(keys.method, claims)
+    token.Header["kid"] = keys.active.id
+    return token.SignedString(keys.active.signKey)
 }
 
-// Проверка токена
+// GenerateChallengeToken токен второго шага входа: подтверждает, что пароль верный,
+// но сессией не является - ValidateToken его не примет
+func GenerateChallengeToken(userID uint) (string, error) {
+    if keys == nil {
+        return "", errors.New("auth keys are not initialized")
+    }
+
+    jti, err := NewID()
+    if err != nil {
+        return "", err
+    }
+
+    claims := Claims{
+        UserID:  userID,
+        Purpose: PurposeTwoFactor,
+        RegisteredClaims: jwt.RegisteredClaims{
+            ID:        jti,
+            Issuer:    keys.issuer,
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(challengeTTL)),
+            IssuedAt:  jwt.NewNumericDate(time.Now()),
+        },
+    }
+
+    token := jwt.NewWithClaims(keys.method, claims)
+    token.Header["kid"] = keys.active.id
+    return token.SignedString(keys.active.signKey)
+}
+
+// ChallengeTTL время жизни токена второго шага входа
+func ChallengeTTL() time.Duration {
+    return challengeTTL
+}
+
+// ValidateChallengeToken проверяет токен второго шага входа
+func ValidateChallengeToken(tokenString string) (*Claims, error) {
+    claims, err := parseToken(tokenString)
+    if err != nil {
+        return nil, err
+    }
+    if claims.Purpose != PurposeTwoFactor {
+        return nil, errors.New("not a challenge token")
+    }
+    return claims, nil
+}
+
+// AccessTokenTTL время жизни access-токена
+func AccessTokenTTL() time.Duration {
+    return keys.accessTTL
+}
+
+// RefreshTokenTTL время жизни refresh-токена
+func RefreshTokenTTL() time.Duration {
+    return keys.refreshTTL
+}
+
+// Проверка access-токена (служебные токены с Purpose не подходят)
 func ValidateToken(tokenString string) (*Claims, error) {
-    token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-        return jwtSecret, nil
-    })
+    claims, err := parseToken(tokenString)
+    if err != nil {
+        return nil, err
+    }
+    if claims.Purpose != "" {
+        return nil, errors.New("not an access token")
+    }
+    return claims, nil
+}
+
+func parseToken(tokenString string) (*Claims, error) {
+    if keys == nil {
+        return nil, errors.New("auth keys are not initialized")
+    }
+
+    token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keys.lookupKey,
+        jwt.WithValidMethods([]string{keys.method.Alg()}),
+        jwt.WithIssuer(keys.issuer),
+    )
 
     if err != nil {
         return nil, err
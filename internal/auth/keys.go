@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"taskflow/internal/config"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// minSecretLength минимальная длина HMAC-секрета (256 бит)
+const minSecretLength = 32
+
+// devSecret используется только в режиме разработки, если ключи не заданы
+const devSecret = "taskflow-dev-only-insecure-secret-do-not-use"
+
+// Заведомо публичные значения: из примеров, документации, старого кода
+var knownWeakSecrets = map[string]bool{
+	"your-secret-key-here":              true,
+	"change_me_to_a_long_random_string": true,
+	"secret":                            true,
+	"changeme":                          true,
+	devSecret:                           true,
+}
+
+type signingKey struct {
+	id        string
+	signKey   interface{} // []byte, *rsa.PrivateKey или ed25519.PrivateKey
+	verifyKey interface{} // []byte, *rsa.PublicKey или ed25519.PublicKey
+}
+
+// keySet набор ключей: активным подписываем, любым из набора проверяем
+type keySet struct {
+	method jwt.SigningMethod
+	active *signingKey
+	keys   map[string]*signingKey
+	order  []string
+	issuer string
+
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+var keys *keySet
+
+// Init загружает ключи подписи JWT из конфигурации.
+// В продакшене отсутствующий или слабый ключ - ошибка запуска.
+func Init(cfg config.AuthConfig, isProd bool) error {
+	method := jwt.GetSigningMethod(cfg.JWTAlgorithm)
+	switch method {
+	case jwt.SigningMethodHS256, jwt.SigningMethodRS256, jwt.SigningMethodEdDSA:
+	default:
+		return fmt.Errorf("unsupported JWT_ALGORITHM %q (use HS256, RS256 or EdDSA)", cfg.JWTAlgorithm)
+	}
+
+	configured := cfg.JWTKeys
+	if len(configured) == 0 {
+		if isProd || method != jwt.SigningMethodHS256 {
+			return errors.New("no JWT signing keys configured (set JWT_SECRET or JWT_KEYS)")
+		}
+		prettyprint.Warn("JWT_SECRET is not set, using insecure development secret")
+		configured = []config.JWTKey{{ID: "dev", Value: devSecret}}
+	}
+
+	set := &keySet{
+		method: method,
+		keys:   make(map[string]*signingKey, len(configured)),
+		issuer: cfg.JWTIssuer,
+
+		accessTTL:  cfg.AccessTokenTTL,
+		refreshTTL: cfg.RefreshTokenTTL,
+	}
+
+	for _, k := range configured {
+		if _, dup := set.keys[k.ID]; dup {
+			return fmt.Errorf("duplicate JWT key id %q", k.ID)
+		}
+
+		key, err := loadKey(method, k, isProd)
+		if err != nil {
+			return fmt.Errorf("JWT key %q: %w", k.ID, err)
+		}
+		set.keys[k.ID] = key
+		set.order = append(set.order, k.ID)
+	}
+
+	activeID := cfg.JWTActiveKID
+	if activeID == "" {
+		activeID = configured[0].ID
+	}
+	set.active = set.keys[activeID]
+	if set.active == nil {
+		return fmt.Errorf("JWT_ACTIVE_KID %q does not match any configured key", activeID)
+	}
+
+	keys = set
+	prettyprint.Info("JWT: %s, %d key(s), active kid=%s", method.Alg(), len(set.keys), activeID)
+	return nil
+}
+
+func loadKey(method jwt.SigningMethod, k config.JWTKey, isProd bool) (*signingKey, error) {
+	switch method {
+	case jwt.SigningMethodHS256:
+		if err := checkSecret(k.Value); err != nil {
+			if isProd {
+				return nil, err
+			}
+			prettyprint.Warn("JWT key %q: %v (allowed in development only)", k.ID, err)
+		}
+		return &signingKey{id: k.ID, signKey: []byte(k.Value), verifyKey: []byte(k.Value)}, nil
+
+	case jwt.SigningMethodRS256:
+		pemBytes, err := os.ReadFile(k.Value)
+		if err != nil {
+			return nil, err
+		}
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		if private.N.BitLen() < 2048 {
+			return nil, errors.New("RSA key must be at least 2048 bits")
+		}
+		return &signingKey{id: k.ID, signKey: private, verifyKey: &private.PublicKey}, nil
+
+	case jwt.SigningMethodEdDSA:
+		pemBytes, err := os.ReadFile(k.Value)
+		if err != nil {
+			return nil, err
+		}
+		private, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		edKey, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("not an Ed25519 private key")
+		}
+		return &signingKey{id: k.ID, signKey: edKey, verifyKey: edKey.Public()}, nil
+	}
+
+	return nil, errors.New("unsupported algorithm")
+}
+
+// checkSecret отсекает короткие и заведомо известные секреты
+func checkSecret(secret string) error {
+	if knownWeakSecrets[strings.TrimSpace(secret)] {
+		return errors.New("secret is a well-known placeholder")
+	}
+	if len(secret) < minSecretLength {
+		return fmt.Errorf("secret must be at least %d bytes", minSecretLength)
+	}
+	return nil
+}
+
+// lookupKey ключ проверки по kid из заголовка токена
+func (s *keySet) lookupKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key.verifyKey, nil
+}
+
+// JWK публичный ключ в формате RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS публичные ключи для /.well-known/jwks.json.
+// ok=false для HS256 - симметричный секрет публиковать нельзя.
+func JWKS() (set []JWK, ok bool) {
+	if keys == nil || keys.method == jwt.SigningMethodHS256 {
+		return nil, false
+	}
+
+	for _, id := range keys.order {
+		jwk, err := toJWK(id, keys.method.Alg(), keys.keys[id].verifyKey)
+		if err != nil {
+			prettyprint.Error("JWKS: %v", err)
+			continue
+		}
+		set = append(set, jwk)
+	}
+	return set, true
+}
+
+func toJWK(kid, alg string, public crypto.PublicKey) (JWK, error) {
+	enc := base64.RawURLEncoding
+
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA", Kid: kid, Use: "sig", Alg: alg,
+			N: enc.EncodeToString(key.N.Bytes()),
+			E: enc.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP", Kid: kid, Use: "sig", Alg: alg,
+			Crv: "Ed25519",
+			X:   enc.EncodeToString(key),
+		}, nil
+	}
+	return JWK{}, fmt.Errorf("unsupported public key type %T", public)
+}
@@ -1,15 +1,183 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
 
-// Хеширование пароля
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"taskflow/internal/config"
+)
+
+// Алгоритмы хеширования паролей (PASSWORD_HASH_ALGORITHM)
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	// bcryptMaxBytes bcrypt учитывает только первые 72 байта пароля
+	bcryptMaxBytes = 72
+)
+
+// passwordHasher текущий алгоритм и его параметры: ими хешируются новые пароли,
+// а хеши с другими параметрами пересчитываются при входе (NeedsRehash).
+type passwordHasher struct {
+	algorithm  string
+	memory     uint32 // argon2id: KiB
+	time       uint32 // argon2id: число проходов
+	threads    uint8  // argon2id: параллелизм
+	bcryptCost int
+}
+
+// По умолчанию - рекомендация OWASP для argon2id (19 MiB, 2 прохода, 1 поток)
+var hasher = &passwordHasher{
+	algorithm:  AlgorithmArgon2id,
+	memory:     19 * 1024,
+	time:       2,
+	threads:    1,
+	bcryptCost: bcrypt.DefaultCost,
+}
+
+// InitPasswordHashing задаёт алгоритм и параметры хеширования из конфигурации
+func InitPasswordHashing(cfg config.PasswordHashConfig) error {
+	h := &passwordHasher{
+		algorithm:  strings.ToLower(cfg.Algorithm),
+		memory:     cfg.Argon2Memory,
+		time:       cfg.Argon2Time,
+		threads:    cfg.Argon2Threads,
+		bcryptCost: cfg.BcryptCost,
+	}
+
+	switch h.algorithm {
+	case AlgorithmArgon2id:
+		if h.time < 1 || h.threads < 1 {
+			return fmt.Errorf("argon2id time and threads must be at least 1")
+		}
+		if h.memory < 8*uint32(h.threads) {
+			return fmt.Errorf("argon2id memory must be at least %d KiB for %d threads", 8*uint32(h.threads), h.threads)
+		}
+	case AlgorithmBcrypt:
+		if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("unsupported PASSWORD_HASH_ALGORITHM %q (use argon2id or bcrypt)", cfg.Algorithm)
+	}
+
+	hasher = h
+	return nil
+}
+
+// MaxPasswordBytes сколько байт пароля учитывает текущий алгоритм (0 - без ограничения)
+func MaxPasswordBytes() int {
+	if hasher.algorithm == AlgorithmBcrypt {
+		return bcryptMaxBytes
+	}
+	return 0
+}
+
+// Хеширование пароля текущим алгоритмом.
+// argon2id - в формате PHC: $argon2id$v=19$m=19456,t=2,p=1$<соль>$<хеш>
 func HashPassword(password string) (string, error) {
-    bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-    return string(bytes), err
+	if hasher.algorithm == AlgorithmBcrypt {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), hasher.bcryptCost)
+		return string(bytes), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, hasher.time, hasher.memory, hasher.threads, argon2KeyLength)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version, hasher.memory, hasher.time, hasher.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
-// Проверка пароля
+// Проверка пароля; алгоритм определяется по самому хешу
 func CheckPasswordHash(password, hash string) bool {
-    err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-    return err == nil
+	if isBcryptHash(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+
+	params, ok := parseArgon2Hash(hash)
+	if !ok {
+		return false
+	}
+	key := argon2.IDKey([]byte(password), params.salt, params.time, params.memory, params.threads, uint32(len(params.key)))
+	return subtle.ConstantTimeCompare(key, params.key) == 1
+}
+
+// NeedsRehash хеш получен другим алгоритмом или с устаревшими параметрами.
+// Вызывать после успешной CheckPasswordHash: тогда пароль можно перехешировать.
+func NeedsRehash(hash string) bool {
+	if isBcryptHash(hash) {
+		if hasher.algorithm != AlgorithmBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != hasher.bcryptCost
+	}
+
+	params, ok := parseArgon2Hash(hash)
+	if !ok || hasher.algorithm != AlgorithmArgon2id {
+		return true
+	}
+	return params.memory != hasher.memory ||
+		params.time != hasher.time ||
+		params.threads != hasher.threads ||
+		len(params.salt) != argon2SaltLength ||
+		len(params.key) != argon2KeyLength
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+type argon2Params struct {
+	memory    uint32
+	time      uint32
+	threads   uint8
+	salt, key []byte
+}
+
+// parseArgon2Hash разбирает строку PHC argon2id
+func parseArgon2Hash(hash string) (*argon2Params, bool) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", соль, хеш
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != AlgorithmArgon2id {
+		return nil, false
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, false
+	}
+
+	params := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return nil, false
+	}
+	if params.time < 1 || params.threads < 1 {
+		return nil, false
+	}
+
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, false
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(params.key) == 0 {
+		return nil, false
+	}
+	return params, true
 }
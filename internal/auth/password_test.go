@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"taskflow/internal/config"
+)
+
+// Дешёвые параметры, чтобы тесты шли быстро
+var (
+	testArgon2 = config.PasswordHashConfig{Algorithm: AlgorithmArgon2id, Argon2Memory: 64, Argon2Time: 1, Argon2Threads: 1}
+	testBcrypt = config.PasswordHashConfig{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost}
+)
+
+// useHashing включает алгоритм на время теста и возвращает прежний
+func useHashing(t *testing.T, cfg config.PasswordHashConfig) {
+	t.Helper()
+	prev := hasher
+	t.Cleanup(func() { hasher = prev })
+	if err := InitPasswordHashing(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustHash(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.PasswordHashConfig
+		prefix string
+	}{
+		{"argon2id", testArgon2, "$argon2id$v=19$m=64,t=1,p=1$"},
+		{"bcrypt", testBcrypt, "$2a$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useHashing(t, tt.cfg)
+			const password = "lunchbox-tornado-violin"
+
+			hash := mustHash(t, password)
+			if !strings.HasPrefix(hash, tt.prefix) {
+				t.Fatalf("hash %q, want prefix %q", hash, tt.prefix)
+			}
+			if !CheckPasswordHash(password, hash) {
+				t.Fatal("correct password rejected")
+			}
+			for _, wrong := range []string{"", "lunchbox-tornado-viola", "Lunchbox-tornado-violin", password + " "} {
+				if CheckPasswordHash(wrong, hash) {
+					t.Errorf("wrong password %q accepted", wrong)
+				}
+			}
+			if NeedsRehash(hash) {
+				t.Error("fresh hash needs rehash")
+			}
+
+			// Соль случайная: одинаковые пароли дают разные хеши
+			if again := mustHash(t, password); again == hash {
+				t.Error("two hashes of the same password are equal")
+			}
+		})
+	}
+}
+
+func TestCheckPasswordHashAcrossAlgorithms(t *testing.T) {
+	// Старые хеши проверяются после смены PASSWORD_HASH_ALGORITHM
+	useHashing(t, testBcrypt)
+	bcryptHash := mustHash(t, "password-one")
+	useHashing(t, testArgon2)
+	argonHash := mustHash(t, "password-two")
+
+	if !CheckPasswordHash("password-one", bcryptHash) {
+		t.Error("bcrypt hash rejected after switching to argon2id")
+	}
+	useHashing(t, testBcrypt)
+	if !CheckPasswordHash("password-two", argonHash) {
+		t.Error("argon2id hash rejected after switching to bcrypt")
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	useHashing(t, testBcrypt)
+	bcryptHash := mustHash(t, "secret-password")
+	useHashing(t, testArgon2)
+	argonHash := mustHash(t, "secret-password")
+
+	tests := []struct {
+		name string
+		cfg  config.PasswordHashConfig
+		hash string
+		want bool
+	}{
+		{"argon2id, same params", testArgon2, argonHash, false},
+		{"bcrypt hash, argon2id configured", testArgon2, bcryptHash, true},
+		{"argon2id, memory changed", config.PasswordHashConfig{Algorithm: AlgorithmArgon2id, Argon2Memory: 128, Argon2Time: 1, Argon2Threads: 1}, argonHash, true},
+		{"argon2id, time changed", config.PasswordHashConfig{Algorithm: AlgorithmArgon2id, Argon2Memory: 64, Argon2Time: 2, Argon2Threads: 1}, argonHash, true},
+		{"argon2id, threads changed", config.PasswordHashConfig{Algorithm: AlgorithmArgon2id, Argon2Memory: 64, Argon2Time: 1, Argon2Threads: 2}, argonHash, true},
+		{"argon2id hash, bcrypt configured", testBcrypt, argonHash, true},
+		{"bcrypt, same cost", testBcrypt, bcryptHash, false},
+		{"bcrypt, cost changed", config.PasswordHashConfig{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost + 1}, bcryptHash, true},
+		{"malformed hash", testArgon2, "not-a-hash", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useHashing(t, tt.cfg)
+			if got := NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArgon2Hash(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	key := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	phc := func(version, params, salt, key string) string {
+		return fmt.Sprintf("$argon2id$%s$%s$%s$%s", version, params, salt, key)
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		ok   bool
+	}{
+		{"valid", phc("v=19", "m=64,t=1,p=1", salt, key), true},
+		{"wrong version", phc("v=16", "m=64,t=1,p=1", salt, key), false},
+		{"no version", phc("19", "m=64,t=1,p=1", salt, key), false},
+		{"empty key", phc("v=19", "m=64,t=1,p=1", salt, ""), false},
+		{"key not base64", phc("v=19", "m=64,t=1,p=1", salt, "!!!"), false},
+		{"salt not base64", phc("v=19", "m=64,t=1,p=1", "!!!", key), false},
+		{"zero time", phc("v=19", "m=64,t=0,p=1", salt, key), false},
+		{"zero threads", phc("v=19", "m=64,t=1,p=0", salt, key), false},
+		{"bad params", phc("v=19", "memory=64", salt, key), false},
+		{"argon2i", strings.Replace(phc("v=19", "m=64,t=1,p=1", salt, key), "argon2id", "argon2i", 1), false},
+		{"missing segment", "$argon2id$v=19$m=64,t=1,p=1$" + key, false},
+		{"extra segment", phc("v=19", "m=64,t=1,p=1", salt, key) + "$x", false},
+		{"no leading dollar", strings.TrimPrefix(phc("v=19", "m=64,t=1,p=1", salt, key), "$"), false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, ok := parseArgon2Hash(tt.hash)
+			if ok != tt.ok {
+				t.Fatalf("parseArgon2Hash ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && (params.memory != 64 || params.time != 1 || params.threads != 1 || len(params.key) != 32) {
+				t.Fatalf("unexpected params %+v", params)
+			}
+			// Испорченный хеш не должен пропускать никакой пароль
+			if !tt.ok && CheckPasswordHash("", tt.hash) {
+				t.Fatal("malformed hash accepted an empty password")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// Секрет из RFC 6238, Appendix B (SHA1): ASCII "12345678901234567890"
+const (
+	rfcSecretRaw    = "12345678901234567890"
+	rfcSecretBase32 = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+)
+
+// Векторы RFC 6238 даны для 8 цифр; 6-значный код - их последние 6 цифр
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},          // 94287082
+	{1111111109, "081804"},  // 07081804
+	{1111111111, "050471"},  // 14050471
+	{1234567890, "005924"},  // 89005924
+	{2000000000, "279037"},  // 69279037
+	{20000000000, "353130"}, // 65353130
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		got := totpCode([]byte(rfcSecretRaw), v.unix/int64(totpPeriod.Seconds()))
+		if got != v.code {
+			t.Errorf("T=%d: totpCode = %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestValidateTOTPRFC6238(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		step, ok := ValidateTOTP(rfcSecretBase32, v.code, time.Unix(v.unix, 0))
+		if !ok {
+			t.Errorf("T=%d: code %s rejected", v.unix, v.code)
+			continue
+		}
+		if want := v.unix / int64(totpPeriod.Seconds()); step != want {
+			t.Errorf("T=%d: step = %d, want %d", v.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	const at = 1111111111 // код 050471, шаг 37037037
+	const step = at / 30
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      int64
+		wantOK   bool
+		wantStep int64
+	}{
+		{"exact", rfcSecretBase32, "050471", at, true, step},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "050471", at, true, step},
+		{"surrounding spaces", rfcSecretBase32, " 050471 ", at, true, step},
+		{"clock behind by one step", rfcSecretBase32, "050471", at + 30, true, step},
+		{"clock ahead by one step", rfcSecretBase32, "050471", at - 30, true, step},
+		{"two steps late", rfcSecretBase32, "050471", at + 60, false, 0},
+		{"two steps early", rfcSecretBase32, "050471", at - 60, false, 0},
+		{"wrong code", rfcSecretBase32, "050472", at, false, 0},
+		{"too short", rfcSecretBase32, "05047", at, false, 0},
+		{"too long", rfcSecretBase32, "0504710", at, false, 0},
+		{"empty", rfcSecretBase32, "", at, false, 0},
+		{"invalid secret", "not base32!", "050471", at, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := ValidateTOTP(tt.secret, tt.code, time.Unix(tt.now, 0))
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := map[string]string{
+		"abcde-fghij":   "abcde-fghij",
+		" ABCDE-FGHIJ ": "abcde-fghij",
+		"abcdefghij":    "abcde-fghij",
+		"abcde fghij":   "abcde-fghij",
+	}
+	for in, want := range tests {
+		if got := NormalizeRecoveryCode(in); got != want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	code, err := NewRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if NormalizeRecoveryCode(code) != code {
+		t.Errorf("generated code %q is not normalized", code)
+	}
+}
@@ -0,0 +1,204 @@
+// internal/bruteforce/guard.go
+package bruteforce
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy пороги для одного вида ключа (аккаунт или IP)
+type Policy struct {
+	FreeAttempts    int           // столько ошибок подряд проходят без задержки
+	BaseDelay       time.Duration // задержка после первой "платной" ошибки, дальше удваивается
+	MaxDelay        time.Duration
+	LockoutAfter    int // после стольких ошибок - блокировка
+	LockoutDuration time.Duration
+	ResetAfter      time.Duration // без ошибок столько времени - счётчик обнуляется
+}
+
+// DefaultAccountPolicy перебор паролей/кодов одного аккаунта
+var DefaultAccountPolicy = Policy{
+	FreeAttempts:    3,
+	BaseDelay:       time.Second,
+	MaxDelay:        5 * time.Minute,
+	LockoutAfter:    10,
+	LockoutDuration: 15 * time.Minute,
+	ResetAfter:      time.Hour,
+}
+
+// DefaultIPPolicy один адрес перебирает разные аккаунты
+var DefaultIPPolicy = Policy{
+	FreeAttempts:    10,
+	BaseDelay:       time.Second,
+	MaxDelay:        5 * time.Minute,
+	LockoutAfter:    50,
+	LockoutDuration: time.Hour,
+	ResetAfter:      time.Hour,
+}
+
+// EventType что именно сработало
+type EventType string
+
+const (
+	EventAccountLocked EventType = "account_locked"
+	EventIPLocked      EventType = "ip_locked"
+)
+
+// Event срабатывание порога - о нём нужно сообщить (лог, уведомление владельцу)
+type Event struct {
+	Type     EventType
+	Account  string
+	IP       string
+	Failures int
+	Until    time.Time
+}
+
+type counter struct {
+	failures    int
+	lastFailure time.Time
+	blockedTill time.Time
+}
+
+// Guard считает неудачные попытки входа/ввода кода по аккаунту и по IP.
+// Состояние в памяти: при перезапуске счётчики обнуляются, этого достаточно.
+type Guard struct {
+	account Policy
+	ip      Policy
+
+	mu       sync.Mutex
+	accounts map[string]*counter
+	ips      map[string]*counter
+}
+
+func NewGuard(account, ip Policy) *Guard {
+	return &Guard{
+		account:  account,
+		ip:       ip,
+		accounts: make(map[string]*counter),
+		ips:      make(map[string]*counter),
+	}
+}
+
+// Allow можно ли сейчас пробовать. Если нет - через сколько.
+// account может быть пустым (например, когда он ещё неизвестен).
+func (g *Guard) Allow(account, ip string) (retryAfter time.Duration, ok bool) {
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if wait := waitFor(g.ips[ip], g.ip, now); wait > 0 {
+		retryAfter = wait
+	}
+	if account != "" {
+		if wait := waitFor(g.accounts[normalize(account)], g.account, now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	return retryAfter, retryAfter == 0
+}
+
+// Fail засчитывает неудачную попытку. Возвращает события, если сработала блокировка.
+func (g *Guard) Fail(account, ip string) []Event {
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var events []Event
+	if c, locked := record(g.ips, ip, g.ip, now); locked {
+		events = append(events, Event{Type: EventIPLocked, IP: ip, Failures: c.failures, Until: c.blockedTill})
+	}
+	if account != "" {
+		if c, locked := record(g.accounts, normalize(account), g.account, now); locked {
+			events = append(events, Event{Type: EventAccountLocked, Account: account, IP: ip, Failures: c.failures, Until: c.blockedTill})
+		}
+	}
+	return events
+}
+
+// Success сбрасывает счётчик аккаунта (IP не трогаем: с него могут перебирать другие аккаунты)
+func (g *Guard) Success(account string) {
+	g.mu.Lock()
+	delete(g.accounts, normalize(account))
+	g.mu.Unlock()
+}
+
+// Run периодически выбрасывает устаревшие счётчики
+func (g *Guard) Run(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			g.mu.Lock()
+			sweep(g.accounts, g.account, now)
+			sweep(g.ips, g.ip, now)
+			g.mu.Unlock()
+		}
+	}
+}
+
+// record увеличивает счётчик; locked=true ровно в момент перехода в блокировку
+func record(counters map[string]*counter, key string, p Policy, now time.Time) (*counter, bool) {
+	c := counters[key]
+	if c == nil || expired(c, p, now) {
+		c = &counter{}
+		counters[key] = c
+	}
+
+	c.failures++
+	c.lastFailure = now
+
+	if c.failures >= p.LockoutAfter {
+		c.blockedTill = now.Add(p.LockoutDuration)
+		return c, c.failures == p.LockoutAfter
+	}
+	if extra := c.failures - p.FreeAttempts; extra > 0 {
+		c.blockedTill = now.Add(backoff(p, extra))
+	}
+	return c, false
+}
+
+// backoff BaseDelay * 2^(n-1), не больше MaxDelay
+func backoff(p Policy, n int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < n; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+func waitFor(c *counter, p Policy, now time.Time) time.Duration {
+	if c == nil || expired(c, p, now) {
+		return 0
+	}
+	if wait := c.blockedTill.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func expired(c *counter, p Policy, now time.Time) bool {
+	return now.After(c.blockedTill) && now.Sub(c.lastFailure) > p.ResetAfter
+}
+
+func sweep(counters map[string]*counter, p Policy, now time.Time) {
+	for key, c := range counters {
+		if expired(c, p, now) {
+			delete(counters, key)
+		}
+	}
+}
+
+func normalize(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
@@ -17,6 +17,10 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+
+	// TrustedProxies адреса/подсети прокси, чьим X-Forwarded-For можно верить.
+	// Пусто - не верим никому: IP клиента берётся из соединения (иначе лимиты обходятся подменой заголовка).
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
@@ -24,15 +28,135 @@ type DatabaseConfig struct {
 }
 
 type EmailConfig struct {
+	Transport    string // resend | smtp | file | log | memory; пусто - resend при наличии ключа, иначе log
 	ResendAPIKey string
 	FromEmail    string
 	TestEmail    string `json:"testEmail"`
+	SMTP         SMTPConfig
+	FileDir      string // каталог для .eml (транспорт file)
+	Outbox       OutboxConfig
+}
+
+// OutboxConfig очередь отправки писем
+type OutboxConfig struct {
+	Workers      int           // сколько писем отправляется одновременно
+	MaxAttempts  int           // после стольких неудач письмо становится мёртвым
+	BaseDelay    time.Duration // пауза после первой неудачи, дальше удваивается
+	MaxDelay     time.Duration
+	PollInterval time.Duration // как часто проверять очередь (новые письма будят обработчиков сразу)
+	Lease        time.Duration // через сколько письмо упавшего обработчика возьмёт другой
+	KeepSent     time.Duration // сколько хранить отправленные письма; 0 - бессрочно
+}
+
+// SMTPConfig подключение к SMTP-серверу
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // пусто - без авторизации
+	Password string
+	TLS      string        // starttls | tls | none
+	Timeout  time.Duration // на весь диалог с сервером
+}
+
+// JWTKey ключ подписи токенов. Для HS256 Value - сам секрет,
+// для RS256/EdDSA - путь к приватному ключу в PEM
+type JWTKey struct {
+	ID    string
+	Value string
+}
+
+type AuthConfig struct {
+	JWTAlgorithm string   // HS256 | RS256 | EdDSA
+	JWTKeys      []JWTKey // все действующие ключи (старые нужны для проверки до истечения токенов)
+	JWTActiveKID string   // каким ключом подписываем новые токены (по умолчанию первый)
+	JWTIssuer    string
+
+	AccessTokenTTL  time.Duration // короткоживущий JWT
+	RefreshTokenTTL time.Duration // сколько живёт семейство refresh-токенов без активности
+
+	// AdminEmails пользователи, которые получают роль администратора при запуске (если email подтверждён)
+	AdminEmails []string
+}
+
+// CookieConfig cookie, которые ставит приложение (сессия, CSRF, вход через OIDC).
+// HttpOnly у всех, остальное настраивается.
+type CookieConfig struct {
+	Domain   string        // пусто - только текущий хост (так возможен префикс __Host-)
+	Path     string        // путь access- и CSRF-cookie
+	Secure   bool          // по умолчанию включено в продакшене
+	SameSite string        // strict | lax | none
+	Lifetime time.Duration // не дольше этого браузер хранит cookie сессии; 0 - по времени жизни токенов
+	Prefix   bool          // __Host-/__Secure- в имени (только при Secure)
+}
+
+// PasswordConfig требования к новым паролям
+type PasswordConfig struct {
+	MinLength  int
+	MaxLength  int
+	MinScore   int    // минимальная оценка стойкости 0..4 (как у zxcvbn)
+	BannedFile string // дополнительный список запрещённых паролей, по одному в строке
+
+	Hash PasswordHashConfig
+}
+
+// PasswordHashConfig алгоритм хеширования паролей. Хеши с другими параметрами
+// продолжают работать и пересчитываются при следующем входе пользователя.
+type PasswordHashConfig struct {
+	Algorithm     string // argon2id | bcrypt
+	Argon2Memory  uint32 // KiB
+	Argon2Time    uint32 // число проходов
+	Argon2Threads uint8
+	BcryptCost    int
+}
+
+type AuditConfig struct {
+	Retention time.Duration // сколько хранить журнал аудита; 0 - бессрочно
+}
+
+// RateLimitRule сколько запросов разрешено клиенту за окно ("10/1m" в переменных окружения)
+type RateLimitRule struct {
+	Requests int
+	Window   time.Duration
+}
+
+// RateLimitConfig лимиты по группам маршрутов. Клиент - пользователь, если он вошёл, иначе IP.
+type RateLimitConfig struct {
+	Enabled bool
+	MaxKeys int // сколько клиентов помнить одновременно; самые давние вытесняются
+
+	Global RateLimitRule // все запросы с одного IP
+	Auth   RateLimitRule // регистрация, вход, коды, сброс пароля
+	Read   RateLimitRule // чтение API
+	Write  RateLimitRule // изменения через API
+}
+
+// OIDCProvider внешний провайдер входа (Google, корпоративный IdP и т.п.).
+// Name - короткий идентификатор в URL: /api/v1/oidc/{name}/login
+type OIDCProvider struct {
+	Name         string
+	DisplayName  string // подпись на кнопке входа
+	Issuer       string // из него берётся {issuer}/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+type OIDCConfig struct {
+	Providers []OIDCProvider
+	Stub      bool // встроенный тестовый провайдер на /dev/oidc (только при DEBUG)
 }
 
 type AppConfig struct {
+    PublicURL   string // адрес, по которому приложение доступно снаружи (для ссылок в письмах)
     Server      ServerConfig
     Database    DatabaseConfig
     Email       EmailConfig
+    Auth        AuthConfig
+    Cookie      CookieConfig
+    Password    PasswordConfig
+    Audit       AuditConfig
+    RateLimit   RateLimitConfig
+    OIDC        OIDCConfig
     Debug       bool   // true = разработка, false = продакшен
     LogLevel    string
 }
@@ -48,12 +172,16 @@ func (c *AppConfig) IsDev() bool {
 
 func Load() *AppConfig {
 	loadEnvFile()
+	debug := getEnvAsBool("DEBUG", false)
 	return &AppConfig{
+		PublicURL: getEnv("PUBLIC_URL", "http://localhost:8080"),
 		Server: ServerConfig{
 			Port:         normalizePort(getEnv("PORT", ":8080")),
 			ReadTimeout:  getEnvAsDuration("READ_TIMEOUT", 10*time.Second),
 			WriteTimeout: getEnvAsDuration("WRITE_TIMEOUT", 10*time.Second),
 			IdleTimeout:  getEnvAsDuration("IDLE_TIMEOUT", 30*time.Second),
+
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Path: getEnv("DB_PATH", "taskflow.db"),
@@ -62,8 +190,74 @@ func Load() *AppConfig {
 			ResendAPIKey: getEnv("RESEND_API_KEY", ""),
 			FromEmail:    getEnv("EMAIL_FROM", "noreply@resend.dev"),
 			TestEmail:    getEnv("TEST_EMAIL", ""),
+			Transport:    getEnv("EMAIL_TRANSPORT", ""),
+			SMTP: SMTPConfig{
+				Host:     getEnv("SMTP_HOST", ""),
+				Port:     getEnvAsInt("SMTP_PORT", 587),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+				TLS:      getEnv("SMTP_TLS", "starttls"),
+				Timeout:  getEnvAsDuration("SMTP_TIMEOUT", 30*time.Second),
+			},
+			FileDir: getEnv("EMAIL_FILE_DIR", "mail"),
+			Outbox: OutboxConfig{
+				Workers:      getEnvAsInt("EMAIL_WORKERS", 4),
+				MaxAttempts:  getEnvAsInt("EMAIL_MAX_ATTEMPTS", 8),
+				BaseDelay:    getEnvAsDuration("EMAIL_RETRY_BASE_DELAY", 30*time.Second),
+				MaxDelay:     getEnvAsDuration("EMAIL_RETRY_MAX_DELAY", time.Hour),
+				PollInterval: getEnvAsDuration("EMAIL_POLL_INTERVAL", 5*time.Second),
+				Lease:        getEnvAsDuration("EMAIL_LEASE", 5*time.Minute),
+				KeepSent:     getEnvAsDuration("EMAIL_KEEP_SENT", 30*24*time.Hour),
+			},
+		},
+		Auth: AuthConfig{
+			JWTAlgorithm: getEnv("JWT_ALGORITHM", "HS256"),
+			JWTKeys:      loadJWTKeys(),
+			JWTActiveKID: getEnv("JWT_ACTIVE_KID", ""),
+			JWTIssuer:    getEnv("JWT_ISSUER", "taskflow"),
+
+			AccessTokenTTL:  getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvAsDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+			AdminEmails: getEnvAsList("ADMIN_EMAILS"),
+		},
+		Cookie: CookieConfig{
+			Domain:   getEnv("COOKIE_DOMAIN", ""),
+			Path:     getEnv("COOKIE_PATH", "/"),
+			Secure:   getEnvAsBool("COOKIE_SECURE", !debug),
+			SameSite: getEnv("COOKIE_SAMESITE", "strict"),
+			Lifetime: getEnvAsDuration("COOKIE_LIFETIME", 0),
+			Prefix:   getEnvAsBool("COOKIE_PREFIX", true),
+		},
+		Password: PasswordConfig{
+			MinLength:  getEnvAsInt("PASSWORD_MIN_LENGTH", 10),
+			MaxLength:  getEnvAsInt("PASSWORD_MAX_LENGTH", 128),
+			MinScore:   getEnvAsInt("PASSWORD_MIN_SCORE", 3),
+			BannedFile: getEnv("PASSWORD_BANNED_FILE", ""),
+			Hash: PasswordHashConfig{
+				Algorithm:     getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+				Argon2Memory:  uint32(getEnvAsInt("PASSWORD_ARGON2_MEMORY", 19*1024)),
+				Argon2Time:    uint32(getEnvAsInt("PASSWORD_ARGON2_TIME", 2)),
+				Argon2Threads: uint8(getEnvAsInt("PASSWORD_ARGON2_THREADS", 1)),
+				BcryptCost:    getEnvAsInt("PASSWORD_BCRYPT_COST", 10),
+			},
+		},
+		Audit: AuditConfig{
+			Retention: getEnvAsDuration("AUDIT_RETENTION", 365*24*time.Hour),
 		},
-		Debug:    getEnvAsBool("DEBUG", false),
+		RateLimit: RateLimitConfig{
+			Enabled: getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			MaxKeys: getEnvAsInt("RATE_LIMIT_MAX_KEYS", 10000),
+			Global:  getEnvAsRateLimit("RATE_LIMIT_GLOBAL", RateLimitRule{Requests: 600, Window: time.Minute}),
+			Auth:    getEnvAsRateLimit("RATE_LIMIT_AUTH", RateLimitRule{Requests: 10, Window: time.Minute}),
+			Read:    getEnvAsRateLimit("RATE_LIMIT_READ", RateLimitRule{Requests: 300, Window: time.Minute}),
+			Write:   getEnvAsRateLimit("RATE_LIMIT_WRITE", RateLimitRule{Requests: 60, Window: time.Minute}),
+		},
+		OIDC: OIDCConfig{
+			Providers: loadOIDCProviders(),
+			Stub:      getEnvAsBool("OIDC_STUB", false),
+		},
+		Debug:    debug,
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 	}
 }
@@ -95,6 +289,61 @@ func loadEnvFile() {
     }
 }
 
+// loadJWTKeys читает ключи из JWT_KEYS ("kid1:value1,kid2:value2")
+// или, для простого случая, один ключ из JWT_SECRET
+func loadJWTKeys() []JWTKey {
+	var keys []JWTKey
+
+	for _, pair := range strings.Split(os.Getenv("JWT_KEYS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, value, ok := strings.Cut(pair, ":")
+		if !ok || id == "" || value == "" {
+			fmt.Printf("⚠️ Ignoring malformed JWT_KEYS entry (expected kid:value)\n")
+			continue
+		}
+		keys = append(keys, JWTKey{ID: strings.TrimSpace(id), Value: strings.TrimSpace(value)})
+	}
+
+	if len(keys) == 0 {
+		if secret := os.Getenv("JWT_SECRET"); secret != "" {
+			keys = append(keys, JWTKey{ID: "default", Value: secret})
+		}
+	}
+	return keys
+}
+
+// loadOIDCProviders читает провайдеров из OIDC_PROVIDERS ("google,corp")
+// и их настройки из OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _SCOPES, _DISPLAY_NAME
+func loadOIDCProviders() []OIDCProvider {
+	var providers []OIDCProvider
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := OIDCProvider{
+			Name:         name,
+			DisplayName:  getEnv(prefix+"DISPLAY_NAME", name),
+			Issuer:       strings.TrimRight(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			fmt.Printf("⚠️ Ignoring OIDC provider %q: %sISSUER and %sCLIENT_ID are required\n", name, prefix, prefix)
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
 // normalizePort приводит порт к формату ":8080"
 func normalizePort(port string) string {
 	port = strings.TrimSpace(port)
@@ -142,3 +391,44 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intVal, err := strconv.Atoi(value); err == nil {
+			return intVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList читает список через запятую, пустые элементы пропускаются
+func getEnvAsList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvAsRateLimit читает лимит вида "10/1m" (запросов / окно)
+func getEnvAsRateLimit(key string, defaultValue RateLimitRule) RateLimitRule {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	count, window, ok := strings.Cut(value, "/")
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if !ok || err != nil || requests <= 0 {
+		fmt.Printf("⚠️ Ignoring malformed %s (expected requests/window, e.g. 10/1m)\n", key)
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || duration <= 0 {
+		fmt.Printf("⚠️ Ignoring malformed %s (expected requests/window, e.g. 10/1m)\n", key)
+		return defaultValue
+	}
+	return RateLimitRule{Requests: requests, Window: duration}
+}
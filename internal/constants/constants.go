@@ -4,4 +4,5 @@ package constants
 // Экспортируемые константы (с большой буквы)
 const (
 	TimeFormat = "2006/01/02 15:04:05"
+	AppName    = "TaskFlow" // так приложение видно пользователю (письма, аутентификатор)
 )
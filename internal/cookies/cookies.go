@@ -0,0 +1,135 @@
+// internal/cookies/cookies.go
+package cookies
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"taskflow/internal/config"
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// Spec cookie приложения. Пустой Path - путь из конфигурации,
+// нулевой SameSite - режим из конфигурации.
+type Spec struct {
+	Name     string
+	Path     string
+	SameSite http.SameSite
+}
+
+var (
+	// Access короткоживущий access-токен для страниц и API
+	Access = Spec{Name: "token"}
+	// Refresh нужен только API (обновление и logout), на страницы он не уходит
+	Refresh = Spec{Name: "refresh_token", Path: "/api/v1"}
+	// CSRF токен double-submit (см. middleware.RequireCSRF)
+	CSRF = Spec{Name: "csrf_token"}
+	// OIDCState привязывает callback провайдера к браузеру. Lax, а не Strict:
+	// callback приходит переходом с сайта провайдера.
+	OIDCState = Spec{Name: "oidc_state", Path: "/api/v1/oidc", SameSite: http.SameSiteLaxMode}
+)
+
+// Policy выставляет cookie по настройкам из config.CookieConfig:
+// HttpOnly всегда, Secure/SameSite/домен/путь - из конфигурации
+type Policy struct {
+	cfg      config.CookieConfig
+	sameSite http.SameSite
+}
+
+func NewPolicy(cfg config.CookieConfig) *Policy {
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+
+	sameSite := http.SameSiteStrictMode
+	switch strings.ToLower(cfg.SameSite) {
+	case "", "strict":
+	case "lax":
+		sameSite = http.SameSiteLaxMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	default:
+		prettyprint.Warn("Unknown COOKIE_SAMESITE %q, using strict", cfg.SameSite)
+	}
+	// Браузеры отбрасывают SameSite=None без Secure
+	if sameSite == http.SameSiteNoneMode && !cfg.Secure {
+		prettyprint.Warn("COOKIE_SAMESITE=none requires COOKIE_SECURE=true, using lax")
+		sameSite = http.SameSiteLaxMode
+	}
+
+	return &Policy{cfg: cfg, sameSite: sameSite}
+}
+
+// Name имя cookie с префиксом. __Host- требует Secure, путь "/" и отсутствие Domain -
+// такую cookie не перезапишет поддомен. Если так нельзя, остаётся __Secure-.
+func (p *Policy) Name(spec Spec) string {
+	if !p.cfg.Secure || !p.cfg.Prefix {
+		return spec.Name
+	}
+	if p.cfg.Domain == "" && p.path(spec) == "/" {
+		return "__Host-" + spec.Name
+	}
+	return "__Secure-" + spec.Name
+}
+
+// Lifetime сколько браузеру хранить cookie с токеном, живущим ttl:
+// не дольше самого токена и не дольше COOKIE_LIFETIME (если задан)
+func (p *Policy) Lifetime(ttl time.Duration) time.Duration {
+	if p.cfg.Lifetime > 0 && p.cfg.Lifetime < ttl {
+		return p.cfg.Lifetime
+	}
+	return ttl
+}
+
+// Get значение cookie или пустая строка
+func (p *Policy) Get(c *gin.Context, spec Spec) string {
+	value, err := c.Cookie(p.Name(spec))
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// Set ставит cookie; maxAge 0 - до закрытия браузера
+func (p *Policy) Set(c *gin.Context, spec Spec, value string, maxAge time.Duration) {
+	cookie := p.cookie(spec, value)
+	if maxAge > 0 {
+		cookie.MaxAge = int(maxAge.Seconds())
+		cookie.Expires = time.Now().Add(maxAge)
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// Clear удаляет cookie
+func (p *Policy) Clear(c *gin.Context, spec Spec) {
+	cookie := p.cookie(spec, "")
+	cookie.MaxAge = -1
+	cookie.Expires = time.Unix(0, 0)
+	http.SetCookie(c.Writer, cookie)
+}
+
+func (p *Policy) cookie(spec Spec, value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     p.Name(spec),
+		Value:    value,
+		Path:     p.path(spec),
+		Secure:   p.cfg.Secure,
+		HttpOnly: true,
+		Domain:   p.cfg.Domain,
+		SameSite: p.sameSite,
+	}
+	if spec.SameSite != 0 {
+		cookie.SameSite = spec.SameSite
+	}
+	return cookie
+}
+
+func (p *Policy) path(spec Spec) string {
+	if spec.Path != "" {
+		return spec.Path
+	}
+	return p.cfg.Path
+}
@@ -15,6 +15,11 @@ import (
 var DB *gorm.DB
 
 func Init() error {
+	return Open("taskflow.db")
+}
+
+// Open подключает базу по пути path и приводит схему к моделям (в тестах - временный файл)
+func Open(path string) error {
 	newLogger := logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags),
 		logger.Config{
@@ -24,7 +29,7 @@ func Init() error {
 			Colorful:                  true,
 		},
 	)
-	db, err := gorm.Open(sqlite.Open("taskflow.db"), &gorm.Config{
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{
 		Logger: newLogger,
 	})
 
@@ -32,7 +37,22 @@ func Init() error {
 		return err
 	}
 
-	err = db.AutoMigrate(&models.User{}, &models.Task{})
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Task{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.Reminder{},
+		&models.RefreshToken{},
+		&models.Session{},
+		&models.RevokedToken{},
+		&models.OneTimeToken{},
+		&models.RecoveryCode{},
+		&models.APIToken{},
+		&models.ExternalIdentity{},
+		&models.AuditEvent{},
+		&models.OutboxEmail{},
+	)
 	if err != nil {
 		return err
 	}
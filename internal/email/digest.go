@@ -0,0 +1,43 @@
+package email
+
+// DigestTask задача в ежедневной сводке
+type DigestTask struct {
+	Title string
+	DueAt string // уже отформатировано в часовом поясе пользователя
+}
+
+// DigestActivity событие из входящих, попавшее в сводку
+type DigestActivity struct {
+	Title string
+	Body  string
+	At    string
+}
+
+// Digest содержимое ежедневной сводки
+type Digest struct {
+	Overdue  []DigestTask
+	DueSoon  []DigestTask
+	Activity []DigestActivity
+}
+
+// IsEmpty нечего отправлять
+func (d *Digest) IsEmpty() bool {
+	return len(d.Overdue) == 0 && len(d.DueSoon) == 0 && len(d.Activity) == 0
+}
+
+// SendNotification отправляет одно уведомление сразу (режим "immediate")
+func (s *Service) SendNotification(to Recipient, title, body, link string) error {
+	return s.send("", TemplateNotification, to, &templateData{
+		Title: title,
+		Body:  body,
+		Link:  s.absURL(link),
+	})
+}
+
+// SendDigest отправляет ежедневную сводку
+func (s *Service) SendDigest(to Recipient, digest *Digest) error {
+	return s.send("", TemplateDigest, to, &templateData{
+		Digest: digest,
+		Link:   s.absURL("/tasks"),
+	})
+}
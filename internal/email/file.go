@@ -0,0 +1,43 @@
+package email
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileSender для разработки: каждое письмо - файл .eml в каталоге,
+// который открывается любым почтовым клиентом
+type FileSender struct {
+	dir string
+}
+
+func NewFileSender(dir string) (*FileSender, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("EMAIL_FILE_DIR is required for the file transport")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create email directory: %w", err)
+	}
+	return &FileSender{dir: dir}, nil
+}
+
+func (s *FileSender) Send(msg *Message) error {
+	body, err := buildMIME(msg)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	// Имя сортируется по времени отправки
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000"), hex.EncodeToString(suffix))
+
+	// Письма с кодами и ссылками входа - только для владельца
+	return os.WriteFile(filepath.Join(s.dir, name), body, 0o600)
+}
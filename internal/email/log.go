@@ -0,0 +1,26 @@
+package email
+
+import (
+	"strings"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// LogSender ничего не отправляет, только пишет письмо в лог (без ключей и серверов)
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(msg *Message) error {
+	prettyprint.Info("📧 Email to %s: %s", strings.Join(msg.To, ", "), msg.Subject)
+
+	// В теле коды и ссылки для входа - только в отладке
+	if msg.Text != "" {
+		prettyprint.Debug("%s", msg.Text)
+	} else {
+		prettyprint.Debug("%s", msg.HTML)
+	}
+	return nil
+}
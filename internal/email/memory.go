@@ -0,0 +1,53 @@
+package email
+
+import "sync"
+
+// MemorySender хранит письма в памяти вместо отправки - для тестов и проверки содержимого писем
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (s *MemorySender) Send(msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *msg
+	copied.To = append([]string(nil), msg.To...)
+	s.messages = append(s.messages, copied)
+	return nil
+}
+
+// Messages копия всех отправленных писем по порядку
+func (s *MemorySender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Last последнее письмо на адрес to (nil, если писем не было)
+func (s *MemorySender) Last(to string) *Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		for _, addr := range s.messages[i].To {
+			if addr == to {
+				msg := s.messages[i]
+				return &msg
+			}
+		}
+	}
+	return nil
+}
+
+// Reset забывает отправленные письма
+func (s *MemorySender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+}
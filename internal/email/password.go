@@ -0,0 +1,23 @@
+package email
+
+// SendPasswordReset отправляет код и ссылку для сброса пароля.
+// link - путь приложения ("/forgot-password?token=..."), превращается в абсолютный адрес.
+func (s *Service) SendPasswordReset(to Recipient, code, link string) error {
+	return s.send("password_reset:"+to.Email+":"+code, TemplatePasswordReset, to, &templateData{
+		Code: code,
+		Link: s.absURL(link),
+	})
+}
+
+// SendEmailChangeCode отправляет на новый адрес код подтверждения смены email
+func (s *Service) SendEmailChangeCode(to Recipient, code string) error {
+	return s.send("email_change:"+to.Email+":"+code, TemplateEmailChange, to, &templateData{Code: code})
+}
+
+// SendMagicLink отправляет одноразовую ссылку и код для входа без пароля
+func (s *Service) SendMagicLink(to Recipient, code, link string) error {
+	return s.send("magic_link:"+to.Email+":"+code, TemplateMagicLink, to, &templateData{
+		Code: code,
+		Link: s.absURL(link),
+	})
+}
@@ -0,0 +1,52 @@
+package email
+
+import "fmt"
+
+// previewData примеры данных для предпросмотра шаблонов
+var previewData = map[string]func(s *Service) *templateData{
+	TemplateVerification: func(s *Service) *templateData {
+		return &templateData{Code: "123456"}
+	},
+	TemplateWelcome: func(s *Service) *templateData {
+		return &templateData{Link: s.absURL("/tasks")}
+	},
+	TemplatePasswordReset: func(s *Service) *templateData {
+		return &templateData{Code: "123456", Link: s.absURL("/forgot-password?token=preview")}
+	},
+	TemplateEmailChange: func(s *Service) *templateData {
+		return &templateData{Code: "123456"}
+	},
+	TemplateMagicLink: func(s *Service) *templateData {
+		return &templateData{Code: "123456", Link: s.absURL("/login?magic=preview")}
+	},
+	TemplateNotification: func(s *Service) *templateData {
+		return &templateData{
+			Title: "Task is due soon",
+			Body:  "\"Prepare the quarterly report\" is due in 1 hour.",
+			Link:  s.absURL("/tasks"),
+		}
+	},
+	TemplateDigest: func(s *Service) *templateData {
+		return &templateData{
+			Digest: &Digest{
+				Overdue: []DigestTask{{Title: "Pay the invoice", DueAt: "18.10.2026 18:00"}},
+				DueSoon: []DigestTask{
+					{Title: "Prepare the quarterly report", DueAt: "20.10.2026 10:00"},
+					{Title: "Call the client", DueAt: "20.10.2026 15:30"},
+				},
+				Activity: []DigestActivity{{Title: "Session", Body: "New sign-in from Firefox on Linux", At: "19.10.2026 08:12"}},
+			},
+			Link: s.absURL("/tasks"),
+		}
+	},
+}
+
+// Preview письмо по шаблону name на языке locale с примерными данными - для проверки вёрстки.
+// Никуда не отправляется.
+func (s *Service) Preview(name, locale string) (*Message, error) {
+	sample, ok := previewData[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+	return s.render(name, Recipient{Email: "preview@example.com", Name: "Alex Johnson", Locale: locale}, sample(s))
+}
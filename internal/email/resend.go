@@ -0,0 +1,23 @@
+package email
+
+import "github.com/resendlabs/resend-go"
+
+// ResendSender отправка через API Resend
+type ResendSender struct {
+	client *resend.Client
+}
+
+func NewResendSender(apiKey string) *ResendSender {
+	return &ResendSender{client: resend.NewClient(apiKey)}
+}
+
+func (s *ResendSender) Send(msg *Message) error {
+	_, err := s.client.Emails.Send(&resend.SendEmailRequest{
+		From:    msg.From,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Html:    msg.HTML,
+		Text:    msg.Text,
+	})
+	return err
+}
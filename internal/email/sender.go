@@ -0,0 +1,99 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"strings"
+
+	"taskflow/internal/config"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// Способы доставки писем (EMAIL_TRANSPORT)
+const (
+	TransportResend = "resend"
+	TransportSMTP   = "smtp"
+	TransportFile   = "file"
+	TransportLog    = "log"
+	TransportMemory = "memory"
+)
+
+// Message готовое к отправке письмо. Text - необязательная текстовая версия:
+// вместе с HTML уходит как multipart/alternative.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	HTML    string
+	Text    string
+
+	// IdempotencyKey одинаковый у повторов одного и того же письма (тот же код, та же ссылка):
+	// очередь отправки ставит такое письмо только один раз. Пусто - письмо всегда новое.
+	IdempotencyKey string
+}
+
+// Sender доставляет письма: Resend, SMTP, файлы .eml, лог или память
+type Sender interface {
+	Send(msg *Message) error
+}
+
+// permanentError ошибка, которую повтор не исправит (неверный адрес и т.п.)
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку отправки как неустранимую повтором
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent повторять отправку бессмысленно: ошибка помечена Permanent
+// или SMTP-сервер окончательно отказал (код 5xx)
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return true
+	}
+	var smtpErr *textproto.Error
+	return errors.As(err, &smtpErr) && smtpErr.Code >= 500
+}
+
+// NewSender создаёт транспорт из конфигурации.
+// В продакшене письма должны уходить на самом деле: log и memory там запрещены.
+func NewSender(cfg config.EmailConfig, isProd bool) (Sender, error) {
+	transport := strings.ToLower(cfg.Transport)
+	if transport == "" {
+		// Без ключа Resend письма просто пишутся в лог - так можно работать локально
+		transport = TransportLog
+		if cfg.ResendAPIKey != "" {
+			transport = TransportResend
+		}
+	}
+
+	if isProd && (transport == TransportLog || transport == TransportMemory) {
+		return nil, fmt.Errorf("EMAIL_TRANSPORT %q does not deliver mail; configure resend, smtp or file in production", transport)
+	}
+
+	switch transport {
+	case TransportResend:
+		if cfg.ResendAPIKey == "" {
+			return nil, fmt.Errorf("RESEND_API_KEY is required for the resend transport")
+		}
+		return NewResendSender(cfg.ResendAPIKey), nil
+	case TransportSMTP:
+		return NewSMTPSender(cfg.SMTP)
+	case TransportFile:
+		return NewFileSender(cfg.FileDir)
+	case TransportLog:
+		prettyprint.Warn("Email transport is \"log\": messages are printed, not delivered")
+		return NewLogSender(), nil
+	case TransportMemory:
+		prettyprint.Warn("Email transport is \"memory\": messages are kept in memory, not delivered")
+		return NewMemorySender(), nil
+	default:
+		return nil, fmt.Errorf("unsupported EMAIL_TRANSPORT %q (use resend, smtp, file, log or memory)", cfg.Transport)
+	}
+}
@@ -0,0 +1,39 @@
+package email
+
+import (
+	"testing"
+
+	"taskflow/internal/config"
+)
+
+func TestNewSenderInProduction(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.EmailConfig
+		ok   bool
+	}{
+		{"no transport configured", config.EmailConfig{}, false},
+		{"log", config.EmailConfig{Transport: TransportLog}, false},
+		{"memory", config.EmailConfig{Transport: TransportMemory}, false},
+		{"resend", config.EmailConfig{Transport: TransportResend, ResendAPIKey: "re_test"}, true},
+		{"resend by api key", config.EmailConfig{ResendAPIKey: "re_test"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSender(tt.cfg, true)
+			if (err == nil) != tt.ok {
+				t.Fatalf("NewSender err = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+
+	// Вне продакшена лог по-прежнему транспорт по умолчанию
+	sender, err := NewSender(config.EmailConfig{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sender.(*LogSender); !ok {
+		t.Fatalf("default sender is %T, want *LogSender", sender)
+	}
+}
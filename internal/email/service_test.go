@@ -0,0 +1,142 @@
+package email
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestSendVerificationCode(t *testing.T) {
+	tests := []struct {
+		locale  string
+		subject string
+		phrase  string
+	}{
+		{"ru", "Код подтверждения", "Здравствуйте, Иван!"},
+		{"en", "Your confirmation code", "Hello, Иван!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			sender := NewMemorySender()
+			svc := NewService(sender, "noreply@example.com", "", "https://taskflow.example.com")
+
+			to := Recipient{Email: "ivan@example.com", Name: "Иван", Locale: tt.locale}
+			if err := svc.SendVerificationCode(to, "482915"); err != nil {
+				t.Fatal(err)
+			}
+
+			msg := sender.Last("ivan@example.com")
+			if msg == nil {
+				t.Fatal("no message sent")
+			}
+			if msg.Subject != tt.subject {
+				t.Errorf("Subject = %q, want %q", msg.Subject, tt.subject)
+			}
+			if msg.From != "noreply@example.com" {
+				t.Errorf("From = %q", msg.From)
+			}
+			if msg.Text == "" {
+				t.Fatal("no text alternative")
+			}
+			for name, body := range map[string]string{"HTML": msg.HTML, "Text": msg.Text} {
+				if !strings.Contains(body, "482915") {
+					t.Errorf("%s has no code", name)
+				}
+				if !strings.Contains(body, tt.phrase) {
+					t.Errorf("%s has no greeting %q", name, tt.phrase)
+				}
+			}
+			if strings.Contains(msg.Text, "<") {
+				t.Errorf("Text contains markup: %q", msg.Text)
+			}
+
+			// Письмо из шаблонов уходит как multipart/alternative с текстовой версией
+			mediaType, order, bodies := readMIME(t, msg)
+			if mediaType != "multipart/alternative" || strings.Join(order, ",") != "text/plain,text/html" {
+				t.Fatalf("Content-Type = %q, parts = %v", mediaType, order)
+			}
+			if !strings.Contains(bodies["text/plain"], "482915") {
+				t.Error("text/plain part has no code")
+			}
+		})
+	}
+}
+
+// readMIME разбирает письмо и возвращает Content-Type и части multipart (тип -> тело)
+func readMIME(t *testing.T, msg *Message) (string, []string, map[string]string) {
+	t.Helper()
+	raw, err := buildMIME(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, msg.Subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != "multipart/alternative" {
+		return mediaType, nil, nil
+	}
+
+	var order []string
+	bodies := map[string]string{}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		order = append(order, partType)
+		bodies[partType] = string(body)
+	}
+	return mediaType, order, bodies
+}
+
+func TestBuildMIME(t *testing.T) {
+	msg := &Message{
+		From:    "noreply@example.com",
+		To:      []string{"ivan@example.com"},
+		Subject: "Код подтверждения",
+		HTML:    "<p>Код: <b>482915</b></p>",
+	}
+
+	if mediaType, _, _ := readMIME(t, msg); mediaType != "text/html" {
+		t.Fatalf("HTML only: Content-Type = %q, want text/html", mediaType)
+	}
+
+	// С текстовой версией - multipart/alternative: текст первым, HTML последним
+	msg.Text = "Код: 482915"
+	mediaType, order, bodies := readMIME(t, msg)
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", mediaType)
+	}
+	if strings.Join(order, ",") != "text/plain,text/html" {
+		t.Fatalf("parts = %v, want [text/plain text/html]", order)
+	}
+	if bodies["text/plain"] != msg.Text || bodies["text/html"] != msg.HTML {
+		t.Fatalf("bodies = %q", bodies)
+	}
+}
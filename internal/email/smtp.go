@@ -0,0 +1,143 @@
+package email
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"taskflow/internal/config"
+)
+
+// Режимы шифрования SMTP (SMTP_TLS)
+const (
+	SMTPTLSStartTLS = "starttls" // обычный порт (587), затем STARTTLS - обязательно
+	SMTPTLSImplicit = "tls"      // TLS с первого байта (465)
+	SMTPTLSNone     = "none"     // без шифрования - только локальные серверы вроде MailHog
+)
+
+// SMTPSender отправка через SMTP-сервер с STARTTLS/TLS и авторизацией
+type SMTPSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	tlsMode  string
+	timeout  time.Duration
+}
+
+func NewSMTPSender(cfg config.SMTPConfig) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP_HOST is required for the smtp transport")
+	}
+
+	tlsMode := strings.ToLower(cfg.TLS)
+	switch tlsMode {
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return nil, fmt.Errorf("unsupported SMTP_TLS %q (use starttls, tls or none)", cfg.TLS)
+	}
+	if tlsMode == SMTPTLSNone && cfg.Username != "" {
+		return nil, fmt.Errorf("SMTP credentials must not be sent without TLS (SMTP_TLS=none)")
+	}
+
+	return &SMTPSender{
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		host:     cfg.Host,
+		username: cfg.Username,
+		password: cfg.Password,
+		tlsMode:  tlsMode,
+		timeout:  cfg.Timeout,
+	}, nil
+}
+
+func (s *SMTPSender) Send(msg *Message) error {
+	body, err := buildMIME(msg)
+	if err != nil {
+		return err
+	}
+	from, err := envelopeAddress(msg.From)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial()
+	if err != nil {
+		return fmt.Errorf("smtp connect: %w", err)
+	}
+	defer client.Close()
+
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	for _, to := range msg.To {
+		rcpt, err := envelopeAddress(to)
+		if err != nil {
+			return err
+		}
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial подключается и при необходимости включает шифрование.
+// Таймаут ограничивает весь диалог с сервером, а не только подключение.
+func (s *SMTPSender) dial() (*smtp.Client, error) {
+	tlsConfig := &tls.Config{ServerName: s.host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: s.timeout}
+
+	var conn net.Conn
+	var err error
+	if s.tlsMode == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", s.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if s.tlsMode == SMTPTLSStartTLS {
+		// Без STARTTLS не отправляем: иначе письма (и пароль SMTP) ушли бы открытым текстом
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("server %s does not support STARTTLS", s.addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
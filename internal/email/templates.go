@@ -0,0 +1,137 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Шаблоны писем: templates/<язык>/<имя>.html и .txt. Общая разметка - layout.html/layout.txt,
+// подпись на нужном языке - <язык>/_layout.*; в .txt ещё задаётся тема письма ("subject").
+//
+//go:embed all:templates
+var templateFS embed.FS
+
+// Имена шаблонов писем
+const (
+	TemplateVerification  = "verification"
+	TemplateWelcome       = "welcome"
+	TemplatePasswordReset = "password_reset"
+	TemplateEmailChange   = "email_change"
+	TemplateMagicLink     = "magic_link"
+	TemplateNotification  = "notification"
+	TemplateDigest        = "digest"
+)
+
+// TemplateNames все шаблоны писем (для предпросмотра)
+var TemplateNames = []string{
+	TemplateVerification,
+	TemplateWelcome,
+	TemplatePasswordReset,
+	TemplateEmailChange,
+	TemplateMagicLink,
+	TemplateNotification,
+	TemplateDigest,
+}
+
+// DefaultLocale язык писем, если язык пользователя неизвестен или не поддерживается
+const DefaultLocale = "ru"
+
+// Locales языки, на которые переведены письма
+var Locales = []string{"ru", "en"}
+
+// templateData данные для шаблона; какие поля заполнены, зависит от письма
+type templateData struct {
+	Locale string
+	AppURL string
+	Name   string
+	Code   string
+	Link   string
+	Title  string
+	Body   string
+	Digest *Digest
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// templates язык -> имя -> шаблон. Разбираются при старте: ошибка в шаблоне - паника, а не письмо без текста.
+var templates = loadTemplates()
+
+func loadTemplates() map[string]map[string]emailTemplate {
+	result := make(map[string]map[string]emailTemplate, len(Locales))
+	for _, locale := range Locales {
+		result[locale] = make(map[string]emailTemplate, len(TemplateNames))
+		for _, name := range TemplateNames {
+			html := htmltemplate.Must(htmltemplate.New("layout.html").ParseFS(templateFS,
+				"templates/layout.html",
+				"templates/"+locale+"/_layout.html",
+				"templates/"+locale+"/"+name+".html",
+			))
+			text := texttemplate.Must(texttemplate.New("layout.txt").ParseFS(templateFS,
+				"templates/layout.txt",
+				"templates/"+locale+"/_layout.txt",
+				"templates/"+locale+"/"+name+".txt",
+			))
+			result[locale][name] = emailTemplate{html: html, text: text}
+		}
+	}
+	return result
+}
+
+// SupportedLocale письма переведены на этот язык
+func SupportedLocale(locale string) bool {
+	for _, l := range Locales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveLocale первый поддерживаемый язык из кандидатов. Кандидат - код языка ("en", "en-US")
+// или заголовок Accept-Language целиком; порядок в заголовке считается порядком предпочтения.
+func ResolveLocale(candidates ...string) string {
+	for _, candidate := range candidates {
+		for _, part := range strings.Split(candidate, ",") {
+			tag, _, _ := strings.Cut(part, ";")
+			primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+			primary = strings.ToLower(strings.TrimSpace(primary))
+			if SupportedLocale(primary) {
+				return primary
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// renderTemplate собирает тему, HTML и текстовую версию письма
+func renderTemplate(name string, data *templateData) (subject, html, text string, err error) {
+	locale := data.Locale
+	if !SupportedLocale(locale) {
+		locale = DefaultLocale
+	}
+	data.Locale = locale
+
+	tmpl, ok := templates[locale][name]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subjectBuf, htmlBuf, textBuf bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subjectBuf, "subject", data); err != nil {
+		return "", "", "", fmt.Errorf("render %s/%s subject: %w", locale, name, err)
+	}
+	if err := tmpl.html.Execute(&htmlBuf, data); err != nil {
+		return "", "", "", fmt.Errorf("render %s/%s.html: %w", locale, name, err)
+	}
+	if err := tmpl.text.Execute(&textBuf, data); err != nil {
+		return "", "", "", fmt.Errorf("render %s/%s.txt: %w", locale, name, err)
+	}
+	return strings.TrimSpace(subjectBuf.String()), htmlBuf.String(), strings.TrimSpace(textBuf.String()) + "\n", nil
+}
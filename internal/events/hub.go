@@ -0,0 +1,173 @@
+// internal/events/hub.go
+package events
+
+import (
+	"sync"
+	"time"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// Типы событий
+const (
+	TaskCreated         = "task.created"
+	TaskUpdated         = "task.updated"
+	TaskDeleted         = "task.deleted"
+	NotificationCreated = "notification.created"
+)
+
+// subscriberBuffer сколько событий может накопиться у медленного подписчика,
+// прежде чем мы его отключим (клиент переподключится с Last-Event-ID)
+const subscriberBuffer = 64
+
+// Event одно событие для пользователя
+type Event struct {
+	ID     uint64      `json:"id"`
+	Type   string      `json:"type"`
+	UserID uint        `json:"-"`
+	Data   interface{} `json:"data"`
+	At     time.Time   `json:"at"`
+}
+
+// Subscription подписка на события одного пользователя.
+// Канал C закрывается при отписке, переполнении или остановке хаба.
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	userID uint
+	closed bool
+}
+
+// Hub простой in-process pub/sub. Хранит кольцевой буфер последних событий,
+// чтобы переподключившийся клиент мог дочитать пропущенное по Last-Event-ID.
+type Hub struct {
+	mu      sync.Mutex
+	nextID  uint64
+	subs    map[uint]map[*Subscription]struct{}
+	history []Event
+	size    int
+	closed  bool
+}
+
+func NewHub(historySize int) *Hub {
+	return &Hub{
+		// ID растут и между перезапусками: старые Last-Event-ID меньше новых,
+		// и клиент получит "reset" (ID больше выданных - тоже, см. Subscribe)
+		nextID:  uint64(time.Now().UnixMilli()) * 1000,
+		subs:    make(map[uint]map[*Subscription]struct{}),
+		history: make([]Event, 0, historySize),
+		size:    historySize,
+	}
+}
+
+// Publish рассылает событие всем подпискам пользователя
+func (h *Hub) Publish(userID uint, eventType string, data interface{}) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	event := Event{
+		ID:     h.nextID,
+		Type:   eventType,
+		UserID: userID,
+		Data:   data,
+		At:     time.Now(),
+	}
+
+	if h.size > 0 {
+		if len(h.history) == h.size {
+			h.history = append(h.history[:0], h.history[1:]...)
+		}
+		h.history = append(h.history, event)
+	}
+
+	for sub := range h.subs[userID] {
+		select {
+		case sub.ch <- event:
+		default:
+			// Подписчик не успевает читать - отключаем его
+			prettyprint.Warn("Event subscriber for user %d is too slow, dropping", userID)
+			h.removeLocked(sub)
+		}
+	}
+
+	return event
+}
+
+// Subscribe подписывает на события пользователя. Если передан lastEventID,
+// возвращает пропущенные события; ok=false значит, что история уже
+// не покрывает этот ID и клиенту нужно перечитать состояние целиком.
+func (h *Hub) Subscribe(userID uint, lastEventID uint64) (sub *Subscription, missed []Event, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, userID: userID}
+
+	if h.closed {
+		sub.closed = true
+		close(ch)
+		return sub, nil, true
+	}
+
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+
+	if lastEventID == 0 {
+		return sub, nil, true
+	}
+
+	// ID больше выданных: клиент пришёл из другого запуска сервера (часы
+	// тогда ушли вперёд) или прислал выдуманный ID - что он пропустил, не узнать
+	if lastEventID > h.nextID {
+		return sub, nil, false
+	}
+
+	if len(h.history) == 0 || lastEventID < h.history[0].ID-1 {
+		// Если событий с тех пор не было вообще, ничего не потеряно
+		return sub, nil, lastEventID == h.nextID
+	}
+
+	for _, e := range h.history {
+		if e.ID > lastEventID && e.UserID == userID {
+			missed = append(missed, e)
+		}
+	}
+	return sub, missed, true
+}
+
+// Unsubscribe отписка (можно вызывать повторно)
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+// Close закрывает все подписки, новые сразу получают закрытый канал.
+// Вызывается при остановке сервера, чтобы долгие SSE-запросы завершились.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.removeLocked(sub)
+		}
+	}
+}
+
+func (h *Hub) removeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+
+	delete(h.subs[sub.userID], sub)
+	if len(h.subs[sub.userID]) == 0 {
+		delete(h.subs, sub.userID)
+	}
+}
@@ -0,0 +1,290 @@
+// internal/handlers/account.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"taskflow/internal/auth"
+	"taskflow/internal/bruteforce"
+	"taskflow/internal/email"
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/password"
+	"taskflow/internal/repository"
+	"taskflow/internal/session"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	emailChangeTTL         = 15 * time.Minute
+	emailChangeMaxAttempts = 5
+)
+
+type AccountHandler struct {
+	userRepo     *repository.UserRepository
+	tokenRepo    *repository.OneTimeTokenRepository
+	sessions     *session.Manager
+	guard        *bruteforce.Guard
+	emailService *email.Service
+	notifier     *notify.Service
+	auditRepo    *repository.AuditRepository
+	policy       *password.Policy
+}
+
+func NewAccountHandler(
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.OneTimeTokenRepository,
+	sessions *session.Manager,
+	guard *bruteforce.Guard,
+	emailService *email.Service,
+	notifier *notify.Service,
+	auditRepo *repository.AuditRepository,
+	policy *password.Policy,
+) *AccountHandler {
+	return &AccountHandler{
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		sessions:     sessions,
+		guard:        guard,
+		emailService: emailService,
+		notifier:     notifier,
+		auditRepo:    auditRepo,
+		policy:       policy,
+	}
+}
+
+// GET /api/v1/me
+func (h *AccountHandler) GetMe(c *gin.Context) {
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, toProfileResponse(user))
+}
+
+// PATCH /api/v1/me {"firstName": "...", "lastName": "...", "locale": "en"}
+func (h *AccountHandler) UpdateMe(c *gin.Context) {
+	var req models.UpdateProfileReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+	if req.Locale != nil {
+		if !email.SupportedLocale(*req.Locale) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Unsupported locale"})
+			return
+		}
+		user.Locale = *req.Locale
+	}
+
+	if err := h.userRepo.UpdateProfile(user.ID, user.FirstName, user.LastName, user.Locale); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toProfileResponse(user))
+}
+
+// POST /api/v1/me/password {"currentPassword": "...", "newPassword": "..."}
+func (h *AccountHandler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	// Пароль проверяется под защитой от перебора: украденный сеанс не должен давать подбирать его
+	if !allowAttempt(c, h.guard, user.Email) {
+		return
+	}
+	if !auth.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		recordFailure(c, h.guard, h.notifier, h.auditRepo, user.Email, user)
+		recordAuthEvent(c, h.auditRepo, models.AuditAuthPasswordChange, models.AuditFailure, user.ID, user.Email, "current password is incorrect")
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Current password is incorrect"})
+		return
+	}
+	h.guard.Success(user.Email)
+
+	if rejectWeakPassword(c, h.policy, req.NewPassword, user) {
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to hash password"})
+		return
+	}
+
+	if err := h.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to change password"})
+		return
+	}
+
+	// Текущее устройство остаётся в системе, остальные - выходят
+	if err := h.sessions.RevokeAll(user.ID, c.GetString("sessionID")); err != nil {
+		fmt.Printf("⚠️ Failed to revoke sessions after password change: %v\n", err)
+	}
+	recordAuthEvent(c, h.auditRepo, models.AuditAuthPasswordChange, models.AuditSuccess, user.ID, user.Email, "other sessions revoked")
+
+	h.notifier.Notify(user.ID, notify.Message{
+		Type: models.NotificationAuthSecurity,
+		Key:  notify.MsgPasswordChange,
+		Data: map[string]interface{}{"IP": c.ClientIP()},
+		Link: "/forgot-password",
+	})
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Password changed"})
+}
+
+// POST /api/v1/me/email {"newEmail": "...", "password": "..."} - шлёт код на новый адрес
+func (h *AccountHandler) ChangeEmail(c *gin.Context) {
+	var req models.ChangeEmailReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	if !allowAttempt(c, h.guard, user.Email) {
+		return
+	}
+	if !auth.CheckPasswordHash(req.Password, user.Password) {
+		recordFailure(c, h.guard, h.notifier, h.auditRepo, user.Email, user)
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Password is incorrect"})
+		return
+	}
+	h.guard.Success(user.Email)
+
+	if req.NewEmail == user.Email {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "This is already your email"})
+		return
+	}
+
+	exists, err := h.userRepo.EmailExists(req.NewEmail)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Database error"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Email already registered"})
+		return
+	}
+
+	code := email.GenerateCode()
+	err = h.tokenRepo.Replace(&models.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   models.PurposeEmailChange,
+		CodeHash:  auth.HashToken(code),
+		Payload:   req.NewEmail,
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to generate code"})
+		return
+	}
+
+	recipient := email.UserRecipient(user)
+	recipient.Email = req.NewEmail
+	if err := h.emailService.SendEmailChangeCode(recipient, code); err != nil {
+		fmt.Printf("⚠️ Failed to queue email change code: %v\n", err)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":  "Verification code sent to the new email",
+		"newEmail": req.NewEmail,
+	})
+}
+
+// POST /api/v1/me/email/confirm {"code": "123456"}
+func (h *AccountHandler) ConfirmEmailChange(c *gin.Context) {
+	var req models.ConfirmEmailChangeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	token, err := h.tokenRepo.GetLatest(user.ID, models.PurposeEmailChange)
+	if err != nil || !token.Active(time.Now()) || !checkTokenCode(h.tokenRepo, token, req.Code, emailChangeMaxAttempts) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid or expired code"})
+		return
+	}
+
+	claimed, err := h.tokenRepo.MarkUsed(token.ID)
+	if err != nil || !claimed {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid or expired code"})
+		return
+	}
+
+	// Пока ждали код, адрес мог занять кто-то другой - уникальный индекс не даст его перезаписать
+	oldEmail, newEmail := user.Email, token.Payload
+	oldRecipient := email.UserRecipient(user)
+	if err := h.userRepo.UpdateEmail(user.ID, newEmail); err != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Email already registered"})
+		return
+	}
+	user.Email = newEmail
+
+	// Старый адрес узнаёт о смене напрямую: уведомления уже уходят на новый
+	title, body, err := notify.Render(oldRecipient.Locale, notify.Message{
+		Key:  notify.MsgEmailChangedTo,
+		Data: map[string]interface{}{"NewEmail": newEmail},
+	})
+	if err == nil {
+		err = h.emailService.SendNotification(oldRecipient, title, body, "/forgot-password")
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Failed to queue old email notice: %v\n", err)
+	}
+
+	h.notifier.Notify(user.ID, notify.Message{
+		Type: models.NotificationAuthSecurity,
+		Key:  notify.MsgEmailChanged,
+		Data: map[string]interface{}{"OldEmail": oldEmail, "NewEmail": newEmail},
+	})
+
+	c.JSON(http.StatusOK, toProfileResponse(user))
+}
+
+func toProfileResponse(user *models.User) models.ProfileResponse {
+	return models.ProfileResponse{
+		ID:         user.ID,
+		Email:      user.Email,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		IsVerified: user.IsVerified,
+		TwoFactor:  user.TOTPEnabled,
+		Role:       user.Role,
+		Timezone:   user.Timezone,
+		Locale:     user.Locale,
+		DigestHour: user.DigestHour,
+		CreatedAt:  user.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,310 @@
+// internal/handlers/admin.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"taskflow/internal/apitoken"
+	"taskflow/internal/auth"
+	"taskflow/internal/models"
+	"taskflow/internal/outbox"
+	"taskflow/internal/repository"
+	"taskflow/internal/session"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	userRepo   *repository.UserRepository
+	auditRepo  *repository.AuditRepository
+	outboxRepo *repository.OutboxRepository
+	mailQueue  *outbox.Queue
+	sessions   *session.Manager
+	apiTokens  *apitoken.Service
+	passwords  *PasswordHandler // письмо со ссылкой сброса при принудительной смене пароля
+}
+
+func NewAdminHandler(
+	userRepo *repository.UserRepository,
+	auditRepo *repository.AuditRepository,
+	outboxRepo *repository.OutboxRepository,
+	mailQueue *outbox.Queue,
+	sessions *session.Manager,
+	apiTokens *apitoken.Service,
+	passwords *PasswordHandler,
+) *AdminHandler {
+	return &AdminHandler{
+		userRepo:   userRepo,
+		auditRepo:  auditRepo,
+		outboxRepo: outboxRepo,
+		mailQueue:  mailQueue,
+		sessions:   sessions,
+		apiTokens:  apiTokens,
+		passwords:  passwords,
+	}
+}
+
+// GET /api/v1/admin/users?q=ivan&role=admin&status=active|disabled|unverified&limit=50&offset=0
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	limit, offset := pagination(c, 50, 200)
+	filter := repository.UserFilter{
+		Query:  strings.TrimSpace(c.Query("q")),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+	}
+
+	users, total, err := h.userRepo.Search(filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get users"})
+		return
+	}
+
+	response := make([]models.AdminUserResponse, len(users))
+	for i := range users {
+		response[i] = toAdminUserResponse(&users[i])
+	}
+	c.JSON(http.StatusOK, models.AdminUsersResponse{
+		Users:  response,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// GET /api/v1/admin/users/:id
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	user, ok := h.loadTarget(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, toAdminUserResponse(user))
+}
+
+// POST /api/v1/admin/users/:id/disable - блокировка: вход закрыт, все сессии и токены отозваны
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	user, ok := h.loadTarget(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+
+	now := time.Now()
+	if err := h.userRepo.SetDisabled(user.ID, &now); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to disable user"})
+		return
+	}
+	h.revokeAccess(user.ID)
+
+	h.audit(c, models.AuditAdminUserDisable, user, "")
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "User disabled"})
+}
+
+// POST /api/v1/admin/users/:id/enable
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	user, ok := h.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userRepo.SetDisabled(user.ID, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to enable user"})
+		return
+	}
+
+	h.audit(c, models.AuditAdminUserEnable, user, "")
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "User enabled"})
+}
+
+// POST /api/v1/admin/users/:id/verify - подтвердить email без кода
+func (h *AdminHandler) VerifyUser(c *gin.Context) {
+	user, ok := h.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userRepo.ForceVerify(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to verify user"})
+		return
+	}
+
+	h.audit(c, models.AuditAdminUserVerify, user, "")
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "User verified"})
+}
+
+// POST /api/v1/admin/users/:id/password-reset - старый пароль перестаёт работать,
+// все сессии завершаются, пользователю уходит письмо для сброса
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	user, ok := h.loadTarget(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+	if user.IsAdmin() {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Cannot reset the password of another admin"})
+		return
+	}
+
+	randomPassword, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to reset password"})
+		return
+	}
+	hashedPassword, err := auth.HashPassword(randomPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to reset password"})
+		return
+	}
+	if err := h.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to reset password"})
+		return
+	}
+	h.revokeAccess(user.ID)
+
+	// Письмо уходит только на подтверждённый адрес
+	h.passwords.sendResetCode(user.Email)
+
+	h.audit(c, models.AuditAdminUserPasswordReset, user, "")
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Password reset, all sessions revoked",
+		"emailSent": user.IsVerified,
+	})
+}
+
+// PUT /api/v1/admin/users/:id/role {"role": "admin"}
+func (h *AdminHandler) SetRole(c *gin.Context) {
+	var req models.SetRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, ok := h.loadTarget(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+
+	if err := h.userRepo.SetRole(user.ID, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update role"})
+		return
+	}
+
+	h.audit(c, models.AuditAdminUserRole, user, fmt.Sprintf("%s -> %s", user.Role, req.Role))
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Role updated"})
+}
+
+// DELETE /api/v1/admin/users/:id - пользователь и все его данные
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	user, ok := h.loadTarget(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+
+	if err := h.userRepo.DeleteWithData(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete user"})
+		return
+	}
+
+	h.audit(c, models.AuditAdminUserDelete, user, user.Email)
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "User deleted"})
+}
+
+// POST /api/v1/admin/users/:id/impersonate - короткий access-токен от имени пользователя.
+// Cookie не ставим, чтобы не затереть сессию самого администратора.
+func (h *AdminHandler) Impersonate(c *gin.Context) {
+	user, ok := h.loadTarget(c)
+	if !ok || !h.notSelf(c, user) {
+		return
+	}
+	if user.IsAdmin() {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Cannot impersonate another admin"})
+		return
+	}
+	if user.Disabled() {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "User is disabled"})
+		return
+	}
+
+	admin := currentAdmin(c)
+	tokens, err := h.sessions.StartImpersonation(user, admin.ID, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to generate token"})
+		return
+	}
+
+	h.audit(c, models.AuditAdminImpersonate, user, "session "+tokens.SessionID)
+	fmt.Printf("🕵️ Admin %d is impersonating user %d (session %s)\n", admin.ID, user.ID, tokens.SessionID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Impersonation session started",
+		"token":         tokens.Access,
+		"expiresIn":     tokens.ExpiresIn,
+		"impersonating": toAdminUserResponse(user),
+	})
+}
+
+// loadTarget пользователь из :id; при ошибке сам отвечает клиенту
+func (h *AdminHandler) loadTarget(c *gin.Context) (*models.User, bool) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return nil, false
+	}
+	user, err := h.userRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "User not found"})
+		return nil, false
+	}
+	return user, true
+}
+
+// notSelf не даёт администратору заблокировать, удалить или разжаловать самого себя
+func (h *AdminHandler) notSelf(c *gin.Context, user *models.User) bool {
+	if user.ID == currentAdmin(c).ID {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "This action cannot be applied to your own account"})
+		return false
+	}
+	return true
+}
+
+// revokeAccess завершает все сессии и отзывает персональные токены пользователя
+func (h *AdminHandler) revokeAccess(userID uint) {
+	if err := h.sessions.RevokeAll(userID, ""); err != nil {
+		fmt.Printf("⚠️ Failed to revoke sessions of user %d: %v\n", userID, err)
+	}
+	if err := h.apiTokens.RevokeAll(userID); err != nil {
+		fmt.Printf("⚠️ Failed to revoke API tokens of user %d: %v\n", userID, err)
+	}
+}
+
+func (h *AdminHandler) audit(c *gin.Context, action string, target *models.User, details string) {
+	actorID := currentAdmin(c).ID
+	targetID := target.ID
+	recordAudit(c, h.auditRepo, models.AuditEvent{
+		Action:  action,
+		UserID:  &targetID,
+		ActorID: &actorID,
+		Details: details,
+	})
+}
+
+// currentAdmin администратор, которого положил в контекст middleware.RequireAdmin
+func currentAdmin(c *gin.Context) *models.User {
+	return c.MustGet("adminUser").(*models.User)
+}
+
+func toAdminUserResponse(user *models.User) models.AdminUserResponse {
+	response := models.AdminUserResponse{
+		ID:         user.ID,
+		Email:      user.Email,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Role:       user.Role,
+		IsVerified: user.IsVerified,
+		TwoFactor:  user.TOTPEnabled,
+		Disabled:   user.Disabled(),
+		CreatedAt:  user.CreatedAt.Format(time.RFC3339),
+	}
+	if user.DisabledAt != nil {
+		response.DisabledAt = user.DisabledAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,118 @@
+// internal/handlers/api_token.go
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"taskflow/internal/apitoken"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APITokenHandler struct {
+	apiTokens *apitoken.Service
+	auditRepo *repository.AuditRepository
+}
+
+func NewAPITokenHandler(apiTokens *apitoken.Service, auditRepo *repository.AuditRepository) *APITokenHandler {
+	return &APITokenHandler{apiTokens: apiTokens, auditRepo: auditRepo}
+}
+
+// GET /api/v1/tokens
+func (h *APITokenHandler) GetTokens(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.apiTokens.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get tokens"})
+		return
+	}
+
+	response := make([]models.APITokenResponse, len(tokens))
+	for i := range tokens {
+		response[i] = toAPITokenResponse(&tokens[i])
+	}
+	c.JSON(http.StatusOK, models.APITokensResponse{Tokens: response})
+}
+
+// POST /api/v1/tokens {"name": "backup script", "scopes": ["tasks:read"], "expiresInDays": 90}
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAPITokenReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	raw, token, err := h.apiTokens.Create(userID, req.Name, req.Scopes, ttl)
+	if err != nil {
+		if errors.Is(err, apitoken.ErrUnknownScope) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "Unknown scope",
+				"scopes": models.APIScopes,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreatedAPITokenResponse{
+		APITokenResponse: toAPITokenResponse(token),
+		Token:            raw,
+	})
+}
+
+// DELETE /api/v1/tokens/:id
+func (h *APITokenHandler) DeleteToken(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	found, err := h.apiTokens.Revoke(userID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to revoke token"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Token not found"})
+		return
+	}
+
+	recordAuthEvent(c, h.auditRepo, models.AuditAuthAPITokenRevoke, models.AuditSuccess, userID, c.GetString("userEmail"), fmt.Sprintf("token %d", id))
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Token revoked"})
+}
+
+func toAPITokenResponse(t *models.APIToken) models.APITokenResponse {
+	response := models.APITokenResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		Prefix:    t.Prefix,
+		Scopes:    t.ScopeList(),
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+	}
+	if t.ExpiresAt != nil {
+		response.ExpiresAt = t.ExpiresAt.Format(time.RFC3339)
+	}
+	if t.LastUsedAt != nil {
+		response.LastUsedAt = t.LastUsedAt.Format(time.RFC3339)
+	}
+	return response
+}
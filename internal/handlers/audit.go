@@ -0,0 +1,158 @@
+// internal/handlers/audit.go
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	auditExportBatch   = 500
+	auditExportTimeout = 10 * time.Minute // выгрузка за год может не уложиться в WriteTimeout сервера
+)
+
+// recordAudit дописывает событие в журнал; IP и User-Agent берутся из запроса.
+// Ошибка записи не ломает сам запрос - только попадает в лог.
+func recordAudit(c *gin.Context, repo *repository.AuditRepository, event models.AuditEvent) {
+	event.IP = c.ClientIP()
+	event.UserAgent = c.Request.UserAgent()
+	if len(event.UserAgent) > 500 {
+		event.UserAgent = event.UserAgent[:500]
+	}
+	if event.Outcome == "" {
+		event.Outcome = models.AuditSuccess
+	}
+
+	if err := repo.Create(&event); err != nil {
+		fmt.Printf("⚠️ Failed to write audit event %s: %v\n", event.Action, err)
+	}
+}
+
+// recordAuthEvent событие входа или учётных данных от имени самого пользователя.
+// userID 0 - аккаунт не найден, тогда в журнале остаётся только email.
+func recordAuthEvent(c *gin.Context, repo *repository.AuditRepository, action, outcome string, userID uint, email, details string) {
+	event := models.AuditEvent{
+		Action:  action,
+		Outcome: outcome,
+		Email:   email,
+		Details: details,
+	}
+	if userID != 0 {
+		event.UserID = &userID
+	}
+	recordAudit(c, repo, event)
+}
+
+// GET /api/v1/admin/audit?action=auth.&outcome=failure&userId=&actorId=&email=&ip=&from=2026-10-01&to=&limit=100&offset=0
+// action - точное действие или группа с точкой на конце; from/to - RFC 3339 или дата
+func (h *AdminHandler) ListAudit(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+	limit, offset := pagination(c, 100, 1000)
+
+	events, total, err := h.auditRepo.Search(filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditEventsResponse{
+		Events: events,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// GET /api/v1/admin/audit/export?<те же фильтры> - JSON Lines, одно событие на строку, старые сверху
+func (h *AdminHandler) ExportAudit(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	// Выгрузка журнала сама попадает в журнал
+	actorID := currentAdmin(c).ID
+	recordAudit(c, h.auditRepo, models.AuditEvent{
+		Action:  models.AuditAdminAuditExport,
+		ActorID: &actorID,
+		Details: c.Request.URL.RawQuery,
+	})
+
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(auditExportTimeout))
+
+	filename := fmt.Sprintf("audit-%s.jsonl", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	written := 0
+	err := h.auditRepo.Each(filter, auditExportBatch, func(event *models.AuditEvent) error {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+		if written++; written%auditExportBatch == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Заголовки уже отправлены - клиент увидит оборванный файл
+		fmt.Printf("⚠️ Audit export interrupted after %d events: %v\n", written, err)
+	}
+}
+
+// auditFilter фильтр журнала из query-параметров; при ошибке отвечает 400
+func auditFilter(c *gin.Context) (repository.AuditFilter, bool) {
+	filter := repository.AuditFilter{
+		Action:  c.Query("action"),
+		Outcome: c.Query("outcome"),
+		Email:   c.Query("email"),
+		IP:      c.Query("ip"),
+	}
+
+	for name, target := range map[string]**uint{"userId": &filter.UserID, "actorId": &filter.ActorID} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid " + name})
+			return filter, false
+		}
+		parsed := uint(id)
+		*target = &parsed
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseAuditTime(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid " + name + ", expected RFC 3339 or YYYY-MM-DD"})
+			return filter, false
+		}
+		*target = parsed
+	}
+	return filter, true
+}
+
+func parseAuditTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
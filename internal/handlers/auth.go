@@ -2,31 +2,71 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"taskflow/internal/auth"
+	"taskflow/internal/bruteforce"
+	"taskflow/internal/cookies"
 	"taskflow/internal/email"
+	"taskflow/internal/middleware"
 	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/password"
 	"taskflow/internal/repository"
+	"taskflow/internal/session"
+	"taskflow/internal/twofactor"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Чем подтверждён вход (details в журнале аудита)
+const (
+	loginMethodPassword  = "password"
+	loginMethodMagicLink = "magic_link"
+	loginMethodOIDC      = "oidc"
+	loginMethodTwoFactor = "totp"
+)
+
 type AuthHandler struct {
 	userRepo     *repository.UserRepository
+	tokenRepo    *repository.OneTimeTokenRepository
+	sessions     *session.Manager
+	jar          *cookies.Policy
+	twoFactor    *twofactor.Service
+	guard        *bruteforce.Guard
 	emailService *email.Service
+	notifier     *notify.Service
+	auditRepo    *repository.AuditRepository
+	policy       *password.Policy
 	testEmail    string // 👈 просто строка, без лишних зависимостей
 }
 
 func NewAuthHandler(
 	userRepo *repository.UserRepository,
+	tokenRepo *repository.OneTimeTokenRepository,
+	sessions *session.Manager,
+	jar *cookies.Policy,
+	twoFactor *twofactor.Service,
+	guard *bruteforce.Guard,
 	emailService *email.Service,
+	notifier *notify.Service,
+	auditRepo *repository.AuditRepository,
+	policy *password.Policy,
 	testEmail string, // 👈 передаём только то что нужно
 ) *AuthHandler {
 	return &AuthHandler{
 		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		sessions:     sessions,
+		jar:          jar,
+		twoFactor:    twoFactor,
+		guard:        guard,
 		emailService: emailService,
+		notifier:     notifier,
+		auditRepo:    auditRepo,
+		policy:       policy,
 		testEmail:    testEmail,
 	}
 }
@@ -39,12 +79,18 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	// Пароль проверяем до любых изменений в базе
+	candidate := &models.User{Email: req.Email, FirstName: req.FirstName, LastName: req.LastName}
+	if rejectWeakPassword(c, h.policy, req.Password, candidate) {
+		return
+	}
+
 	// ===== 1. ЕСЛИ ЭТО ТЕСТОВЫЙ EMAIL - УДАЛЯЕМ СТАРОГО ПОЛЬЗОВАТЕЛЯ =====
 	if req.Email == h.testEmail {
 		existing, _ := h.userRepo.GetByEmail(req.Email)
 		if existing != nil {
-			// Удаляем старого пользователя (даже если верифицирован!)
-			if err := h.userRepo.Delete(existing.ID); err != nil {
+			// Удаляем старого пользователя вместе с его данными (даже если верифицирован!)
+			if err := h.userRepo.DeleteWithData(existing.ID); err != nil {
 				fmt.Printf("⚠️ Failed to delete test user: %v\n", err)
 			} else {
 				fmt.Println("🧹 Тестовый пользователь удалён для перерегистрации")
@@ -62,7 +108,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 		// Если пользователь есть, но не верифицирован - удаляем
 		if existingUser != nil && !existingUser.IsVerified {
-			if err := h.userRepo.Delete(existingUser.ID); err != nil {
+			if err := h.userRepo.DeleteWithData(existingUser.ID); err != nil {
 				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to process registration"})
 				return
 			}
@@ -88,6 +134,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		IsVerified:  false,
 		VerifyCode:  verificationCode,
 		CodeExpires: time.Now().Add(15 * time.Minute),
+		Locale:      email.ResolveLocale(req.Locale, c.GetHeader("Accept-Language")),
 	}
 
 	if err := h.userRepo.Create(user); err != nil {
@@ -96,11 +143,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// ===== 4. ОТПРАВЛЯЕМ КОД =====
-	go func() {
-		if err := h.emailService.SendVerificationCode(user.Email, verificationCode); err != nil {
-			fmt.Printf("Failed to send verification email: %v\n", err)
-		}
-	}()
+	if err := h.emailService.SendVerificationCode(email.UserRecipient(user), verificationCode); err != nil {
+		fmt.Printf("⚠️ Failed to queue verification email: %v\n", err)
+	}
 
 	// ===== 5. ОТВЕЧАЕМ =====
 	c.JSON(http.StatusCreated, gin.H{
@@ -118,14 +163,21 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if !allowAttempt(c, h.guard, req.Email) {
+		return
+	}
+
 	user, err := h.userRepo.GetByEmail(req.Email)
 	if err != nil || user == nil {
+		recordFailure(c, h.guard, h.notifier, h.auditRepo, req.Email, nil)
+		h.audit(c, models.AuditAuthLogin, models.AuditFailure, 0, req.Email, "unknown email")
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid email or password"})
 		return
 	}
 
 	// 👇 НОВАЯ ПРОВЕРКА
 	if !user.IsVerified {
+		h.audit(c, models.AuditAuthLogin, models.AuditFailure, user.ID, user.Email, "email not verified")
 		c.JSON(http.StatusForbidden, gin.H{
 			"error":   "Email not verified",
 			"email":   user.Email,
@@ -135,23 +187,114 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	if !auth.CheckPasswordHash(req.Password, user.Password) {
+		recordFailure(c, h.guard, h.notifier, h.auditRepo, req.Email, user)
+		h.audit(c, models.AuditAuthLogin, models.AuditFailure, user.ID, user.Email, "invalid password")
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid email or password"})
 		return
 	}
 
-	token, _ := auth.GenerateToken(user.ID, user.Email)
+	// Пароль известен только сейчас: хеш старым алгоритмом или параметрами пересчитываем
+	if auth.NeedsRehash(user.Password) {
+		h.rehashPassword(user, req.Password)
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":  "Login successful",
-		"token":    token,
-		"redirect": "/tasks",
-		"user": gin.H{
-			"id":        user.ID,
-			"firstName": user.FirstName,
-			"lastName":  user.LastName,
-			"email":     user.Email,
-		},
+	h.beginLogin(c, user, loginMethodPassword)
+}
+
+// rehashPassword сохраняет хеш пароля с текущими параметрами. Ошибка не мешает входу.
+func (h *AuthHandler) rehashPassword(user *models.User, password string) {
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		fmt.Printf("⚠️ Failed to rehash password for user %d: %v\n", user.ID, err)
+		return
+	}
+	if err := h.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
+		fmt.Printf("⚠️ Failed to store rehashed password for user %d: %v\n", user.ID, err)
+		return
+	}
+	user.Password = hashedPassword
+	fmt.Printf("🔐 Password hash upgraded for user %d\n", user.ID)
+}
+
+// beginLogin вызывается, когда первый фактор (пароль или ссылка из письма) проверен.
+// С включённой 2FA это только первый шаг: сессии ещё нет,
+// клиент получает короткоживущий токен для POST /api/v1/login/2fa.
+// method - чем подтверждён первый фактор, для журнала аудита.
+func (h *AuthHandler) beginLogin(c *gin.Context, user *models.User, method string) {
+	if rejectDisabled(c, user) {
+		h.audit(c, models.AuditAuthLogin, models.AuditFailure, user.ID, user.Email, "account disabled, method="+method)
+		return
+	}
+
+	if user.TOTPEnabled {
+		challenge, err := auth.GenerateChallengeToken(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Two-factor authentication required",
+			"twoFactorRequired": true,
+			"challengeToken":    challenge,
+			"expiresIn":         int(auth.ChallengeTTL().Seconds()),
+		})
+		return
+	}
+
+	h.completeLogin(c, user, method)
+}
+
+// POST /api/v1/login/2fa {"challengeToken": "...", "code": "123456"}
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	claims, err := auth.ValidateChallengeToken(req.ChallengeToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Login challenge expired, please log in again"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Login challenge expired, please log in again"})
+		return
+	}
+
+	// Пароль и код 2FA делят один счётчик аккаунта
+	if !allowAttempt(c, h.guard, user.Email) {
+		return
+	}
+
+	if err := h.twoFactor.Verify(user, req.Code); err != nil {
+		if errors.Is(err, twofactor.ErrInvalidCode) {
+			recordFailure(c, h.guard, h.notifier, h.auditRepo, user.Email, user)
+			h.audit(c, models.AuditAuthLogin, models.AuditFailure, user.ID, user.Email, "invalid two-factor code")
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid two-factor code"})
+			return
+		}
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	h.completeLogin(c, user, loginMethodTwoFactor)
+}
+
+// completeLogin последний шаг входа: уведомление о входе и новая сессия
+func (h *AuthHandler) completeLogin(c *gin.Context, user *models.User, method string) {
+	h.guard.Success(user.Email)
+	h.audit(c, models.AuditAuthLogin, models.AuditSuccess, user.ID, user.Email, "method="+method)
+
+	h.notifier.Notify(user.ID, notify.Message{
+		Type: models.NotificationAuthLogin,
+		Key:  notify.MsgLogin,
+		Data: map[string]interface{}{"IP": c.ClientIP(), "Device": c.Request.UserAgent()},
 	})
+
+	h.respondWithSession(c, user, "Login successful")
 }
 
 // POST /api/v1/verify
@@ -166,9 +309,15 @@ func (h *AuthHandler) Verify(c *gin.Context) {
 		return
 	}
 
+	if !allowAttempt(c, h.guard, req.Email) {
+		return
+	}
+
 	// Получаем пользователя
 	user, err := h.userRepo.GetByEmail(req.Email)
 	if err != nil || user == nil {
+		recordFailure(c, h.guard, h.notifier, h.auditRepo, req.Email, nil)
+		h.audit(c, models.AuditAuthVerify, models.AuditFailure, 0, req.Email, "unknown email")
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid or expired code"})
 		return
 	}
@@ -183,37 +332,28 @@ func (h *AuthHandler) Verify(c *gin.Context) {
 
 	if !verified {
 		fmt.Printf("❌ Code mismatch or expired\n")
+		recordFailure(c, h.guard, h.notifier, h.auditRepo, req.Email, user)
+		h.audit(c, models.AuditAuthVerify, models.AuditFailure, user.ID, user.Email, "invalid or expired code")
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid or expired code"})
 		return
 	}
 
-	// Генерируем JWT
-	token, err := auth.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to generate token"})
-		return
+	h.audit(c, models.AuditAuthVerify, models.AuditSuccess, user.ID, user.Email, "")
+
+	// Приветственное письмо - через очередь отправки, повторный Verify не продублирует его
+	if err := h.emailService.SendWelcomeEmail(email.UserRecipient(user)); err != nil {
+		fmt.Printf("⚠️ Failed to queue welcome email: %v\n", err)
 	}
 
-	// Отправляем приветственное письмо (асинхронно)
-	go func() {
-		fullName := user.FirstName + " " + user.LastName
-		if err := h.emailService.SendWelcomeEmail(user.Email, fullName); err != nil {
-			fmt.Printf("Failed to send welcome email: %v\n", err)
-		}
-	}()
+	h.notifier.Notify(user.ID, notify.Message{
+		Type: models.NotificationAuthWelcome,
+		Key:  notify.MsgWelcome,
+		Link: "/tasks",
+	})
 
 	// Успех - логиним пользователя
-	c.JSON(http.StatusOK, gin.H{
-		"message":  "Email verified successfully",
-		"token":    token,
-		"redirect": "/tasks",
-		"user": gin.H{
-			"id":        user.ID,
-			"firstName": user.FirstName,
-			"lastName":  user.LastName,
-			"email":     user.Email,
-		},
-	})
+	h.guard.Success(user.Email)
+	h.respondWithSession(c, user, "Email verified successfully")
 }
 
 // POST /api/v1/resend-code
@@ -229,12 +369,14 @@ func (h *AuthHandler) ResendCode(c *gin.Context) {
 
 	user, err := h.userRepo.GetByEmail(req.Email)
 	if err != nil || user == nil {
+		h.audit(c, models.AuditAuthResendCode, models.AuditFailure, 0, req.Email, "unknown email")
 		// Не говорим, что пользователь не найден (безопасность)
 		c.JSON(http.StatusOK, gin.H{"message": "If email exists, code will be sent"})
 		return
 	}
 
 	if user.IsVerified {
+		h.audit(c, models.AuditAuthResendCode, models.AuditFailure, user.ID, user.Email, "already verified")
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Email already verified"})
 		return
 	}
@@ -249,23 +391,42 @@ func (h *AuthHandler) ResendCode(c *gin.Context) {
 		return
 	}
 
-	// Отправляем
-	go h.emailService.SendVerificationCode(user.Email, newCode)
+	// Отправляем (письмо сохраняется в очереди и доставляется с повторами)
+	if err := h.emailService.SendVerificationCode(email.UserRecipient(user), newCode); err != nil {
+		fmt.Printf("⚠️ Failed to queue verification email: %v\n", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to send code"})
+		return
+	}
+	h.audit(c, models.AuditAuthResendCode, models.AuditSuccess, user.ID, user.Email, "")
 
 	c.JSON(http.StatusOK, gin.H{"message": "Code sent successfully"})
 }
 
+// POST /api/v1/logout
 func (h *AuthHandler) Logout(c *gin.Context) {
+	// Завершаем текущую сессию: access-токен перестаёт приниматься сразу,
+	// refresh-токеном больше нельзя продлить сессию
+	if claims, err := auth.ValidateToken(middleware.ExtractToken(c, h.jar)); err == nil && claims.SessionID != "" {
+		if _, err := h.sessions.Revoke(claims.UserID, claims.SessionID); err != nil {
+			fmt.Printf("⚠️ Failed to revoke session: %v\n", err)
+		}
+		if err := h.sessions.RevokeAccessToken(claims); err != nil {
+			fmt.Printf("⚠️ Failed to revoke access token: %v\n", err)
+		}
+		h.audit(c, models.AuditAuthLogout, models.AuditSuccess, claims.UserID, claims.Email, "session "+claims.SessionID)
+	} else if raw := h.jar.Get(c, cookies.Refresh); raw != "" {
+		// access-токен уже истёк - находим сессию по refresh-токену
+		userID, err := h.sessions.RevokeByRefresh(raw)
+		if err != nil {
+			fmt.Printf("⚠️ Failed to revoke session: %v\n", err)
+		}
+		if userID != 0 {
+			h.audit(c, models.AuditAuthLogout, models.AuditSuccess, userID, "", "by refresh token")
+		}
+	}
+
 	// Очищаем cookie
-	c.SetCookie(
-		"token",
-		"",
-		-1, // maxAge = -1 → удалить
-		"/",
-		"",
-		false, // secure (в dev false)
-		true,  // httpOnly
-	)
+	clearAuthCookies(c, h.jar)
 
 	// Отвечаем
 	c.JSON(http.StatusOK, gin.H{
@@ -273,3 +434,83 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		"redirect": "/login",
 	})
 }
+
+// POST /api/v1/token/refresh {"refreshToken": "..."} (или cookie refresh_token).
+// Новые токены в теле получают только API-клиенты, браузер - в cookie.
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	raw := req.RefreshToken
+	if raw == "" {
+		raw = h.jar.Get(c, cookies.Refresh)
+	}
+	if raw == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Refresh token required"})
+		return
+	}
+
+	tokens, _, err := h.sessions.Refresh(raw, c.ClientIP())
+	switch {
+	case errors.Is(err, session.ErrRefreshReuse):
+		fmt.Printf("🚨 Refresh token reuse detected: ip=%s\n", c.ClientIP())
+		h.audit(c, models.AuditAuthRefreshReuse, models.AuditFailure, 0, "", "session revoked")
+		clearAuthCookies(c, h.jar)
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Refresh token reuse detected"})
+		return
+	case errors.Is(err, session.ErrInvalidRefresh):
+		clearAuthCookies(c, h.jar)
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid refresh token"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to refresh token"})
+		return
+	}
+
+	setAuthCookies(c, h.jar, tokens.Access, tokens.Refresh)
+	c.JSON(http.StatusOK, sessionBody(c, tokens, gin.H{}))
+}
+
+// respondWithSession логинит пользователя: заводит сессию, выдаёт токены, ставит cookie и отвечает
+func (h *AuthHandler) respondWithSession(c *gin.Context, user *models.User, message string) {
+	if rejectDisabled(c, user) {
+		return
+	}
+
+	tokens, err := h.sessions.Start(user, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to generate token"})
+		return
+	}
+
+	setAuthCookies(c, h.jar, tokens.Access, tokens.Refresh)
+	c.JSON(http.StatusOK, sessionBody(c, tokens, gin.H{
+		"message":  message,
+		"redirect": "/tasks",
+		"user": gin.H{
+			"id":        user.ID,
+			"firstName": user.FirstName,
+			"lastName":  user.LastName,
+			"email":     user.Email,
+		},
+	}))
+}
+
+// rejectDisabled отвечает 403, если аккаунт заблокирован администратором.
+// Вызывается только после проверки пароля/кода, чтобы не раскрывать статус аккаунта.
+func rejectDisabled(c *gin.Context, user *models.User) bool {
+	if !user.Disabled() {
+		return false
+	}
+	c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Account is disabled"})
+	return true
+}
+
+func (h *AuthHandler) audit(c *gin.Context, action, outcome string, userID uint, email, details string) {
+	recordAuthEvent(c, h.auditRepo, action, outcome, userID, email, details)
+}
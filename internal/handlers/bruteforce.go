@@ -0,0 +1,65 @@
+// internal/handlers/bruteforce.go
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"taskflow/internal/bruteforce"
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// allowAttempt отвечает 429 с Retry-After, если аккаунт или IP сейчас заблокированы
+func allowAttempt(c *gin.Context, guard *bruteforce.Guard, account string) bool {
+	retryAfter, ok := guard.Allow(account, c.ClientIP())
+	if ok {
+		return true
+	}
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":      "Too many failed attempts, please try again later",
+		"retryAfter": seconds,
+	})
+	return false
+}
+
+// recordFailure засчитывает неудачную попытку и сообщает о сработавших блокировках
+// (лог, журнал аудита, уведомление владельцу).
+// user может быть nil (аккаунт не найден) - тогда уведомлять некого.
+func recordFailure(c *gin.Context, guard *bruteforce.Guard, notifier *notify.Service, auditRepo *repository.AuditRepository, account string, user *models.User) {
+	var userID uint
+	if user != nil {
+		userID = user.ID
+	}
+
+	for _, event := range guard.Fail(account, c.ClientIP()) {
+		switch event.Type {
+		case bruteforce.EventAccountLocked:
+			fmt.Printf("🚨 Security: account %q locked after %d failed attempts (last IP %s) until %s\n",
+				event.Account, event.Failures, event.IP, event.Until.Format("15:04:05"))
+			recordAuthEvent(c, auditRepo, models.AuditAuthLockout, models.AuditFailure, userID, event.Account,
+				fmt.Sprintf("account locked after %d failed attempts until %s", event.Failures, event.Until.UTC().Format(time.RFC3339)))
+			if user != nil {
+				notifier.Notify(user.ID, notify.Message{
+					Type: models.NotificationAuthSecurity,
+					Key:  notify.MsgLoginLocked,
+					Data: map[string]interface{}{"IP": event.IP, "Until": event.Until},
+					Link: "/forgot-password",
+				})
+			}
+		case bruteforce.EventIPLocked:
+			fmt.Printf("🚨 Security: IP %s blocked after %d failed attempts until %s\n",
+				event.IP, event.Failures, event.Until.Format("15:04:05"))
+			recordAuthEvent(c, auditRepo, models.AuditAuthLockout, models.AuditFailure, 0, "",
+				fmt.Sprintf("IP %s locked after %d failed attempts until %s", event.IP, event.Failures, event.Until.UTC().Format(time.RFC3339)))
+		}
+	}
+}
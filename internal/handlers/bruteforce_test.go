@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taskflow/internal/bruteforce"
+	"taskflow/internal/database"
+	"taskflow/internal/email"
+	"taskflow/internal/events"
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecordFailureAuditsLockouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := database.Open(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Email: "owner@example.com", Password: "-", IsVerified: true}
+	if err := database.DB.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := repository.NewUserRepository()
+	emailService := email.NewService(email.NewMemorySender(), "noreply@example.com", "", "http://app.test")
+	notifier := notify.NewService(repository.NewNotificationRepository(), repository.NewNotificationPreferenceRepository(), userRepo, emailService, events.NewHub(10))
+	auditRepo := repository.NewAuditRepository()
+
+	// Аккаунт блокируется на второй ошибке, IP - на третьей
+	guard := bruteforce.NewGuard(
+		bruteforce.Policy{FreeAttempts: 10, LockoutAfter: 2, LockoutDuration: time.Minute, ResetAfter: time.Hour},
+		bruteforce.Policy{FreeAttempts: 10, LockoutAfter: 3, LockoutDuration: time.Minute, ResetAfter: time.Hour},
+	)
+	fail := func() {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/api/v1/login", nil)
+		c.Request.RemoteAddr = "203.0.113.7:5000"
+		recordFailure(c, guard, notifier, auditRepo, user.Email, user)
+	}
+
+	for i := 0; i < 3; i++ {
+		fail()
+	}
+
+	var lockouts []models.AuditEvent
+	if err := database.DB.Where("action = ?", models.AuditAuthLockout).Order("id").Find(&lockouts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(lockouts) != 2 {
+		t.Fatalf("got %d lockout events, want 2 (account and IP)", len(lockouts))
+	}
+
+	account, ip := lockouts[0], lockouts[1]
+	if account.UserID == nil || *account.UserID != user.ID || account.Email != user.Email || account.Outcome != models.AuditFailure {
+		t.Errorf("account lockout event %+v", account)
+	}
+	if ip.UserID != nil || ip.IP != "203.0.113.7" {
+		t.Errorf("IP lockout event %+v", ip)
+	}
+}
@@ -0,0 +1,64 @@
+// internal/handlers/context.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID достаёт ID пользователя, положенный AuthMiddleware.
+// Если его нет - сразу отвечает 401.
+func currentUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "User not authenticated"})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// loadCurrentUser загружает пользователя из токена; при ошибке сам отвечает клиенту
+func loadCurrentUser(c *gin.Context, userRepo *repository.UserRepository) (*models.User, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+
+	user, err := userRepo.GetByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "User not found"})
+		return nil, false
+	}
+	return user, true
+}
+
+// paramID парсит числовой :id из URL, при ошибке отвечает 400
+func paramID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// pagination читает ?limit=&offset= с разумными границами
+func pagination(c *gin.Context, defaultLimit, maxLimit int) (limit, offset int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	offset, err = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
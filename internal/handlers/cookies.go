@@ -0,0 +1,39 @@
+// internal/handlers/cookies.go
+package handlers
+
+import (
+	"taskflow/internal/auth"
+	"taskflow/internal/cookies"
+	"taskflow/internal/session"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setAuthCookies кладёт access и refresh токены в cookie
+func setAuthCookies(c *gin.Context, jar *cookies.Policy, accessToken, refreshToken string) {
+	jar.Set(c, cookies.Access, accessToken, jar.Lifetime(auth.AccessTokenTTL()))
+	jar.Set(c, cookies.Refresh, refreshToken, jar.Lifetime(auth.RefreshTokenTTL()))
+}
+
+// clearAuthCookies удаляет оба cookie
+func clearAuthCookies(c *gin.Context, jar *cookies.Policy) {
+	jar.Clear(c, cookies.Access)
+	jar.Clear(c, cookies.Refresh)
+}
+
+// browserRequest запрос пришёл со страницы в браузере. Origin у POST и
+// Sec-Fetch-* браузер ставит сам, и скрипт на странице их не уберёт.
+func browserRequest(c *gin.Context) bool {
+	return c.GetHeader("Origin") != "" || c.GetHeader("Sec-Fetch-Site") != ""
+}
+
+// sessionBody добавляет токены в тело ответа. Браузеру хватает HttpOnly cookie:
+// токен в JSON можно украсть через XSS, поэтому его получают только API-клиенты.
+func sessionBody(c *gin.Context, tokens *session.Tokens, body gin.H) gin.H {
+	body["expiresIn"] = tokens.ExpiresIn
+	if !browserRequest(c) {
+		body["token"] = tokens.Access
+		body["refreshToken"] = tokens.Refresh
+	}
+	return body
+}
@@ -0,0 +1,57 @@
+// internal/handlers/email_preview.go
+package handlers
+
+import (
+	"net/http"
+	"taskflow/internal/email"
+	"taskflow/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailPreviewHandler предпросмотр шаблонов писем с примерными данными - только в режиме разработки
+type EmailPreviewHandler struct {
+	emailService *email.Service
+}
+
+func NewEmailPreviewHandler(emailService *email.Service) *EmailPreviewHandler {
+	return &EmailPreviewHandler{emailService: emailService}
+}
+
+// GET /dev/emails - какие шаблоны и языки есть
+func (h *EmailPreviewHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"templates": email.TemplateNames,
+		"locales":   email.Locales,
+	})
+}
+
+// GET /dev/emails/:name?locale=en&format=html|text|json - письмо так, как его увидит получатель
+func (h *EmailPreviewHandler) Preview(c *gin.Context) {
+	locale := c.DefaultQuery("locale", email.DefaultLocale)
+	if !email.SupportedLocale(locale) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Unsupported locale"})
+		return
+	}
+
+	msg, err := h.emailService.Preview(c.Param("name"), locale)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("format", "html") {
+	case "html":
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(msg.HTML))
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(msg.Text))
+	case "json":
+		c.JSON(http.StatusOK, gin.H{
+			"subject": msg.Subject,
+			"html":    msg.HTML,
+			"text":    msg.Text,
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid format (use html, text or json)"})
+	}
+}
@@ -0,0 +1,89 @@
+// internal/handlers/events.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"taskflow/internal/events"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval как часто шлём комментарий-пинг, чтобы прокси
+// не закрывали "молчащее" соединение
+const heartbeatInterval = 25 * time.Second
+
+type EventsHandler struct {
+	hub *events.Hub
+}
+
+func NewEventsHandler(hub *events.Hub) *EventsHandler {
+	return &EventsHandler{hub: hub}
+}
+
+// GET /api/v1/events (text/event-stream)
+func (h *EventsHandler) Stream(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// EventSource присылает Last-Event-ID сам при переподключении
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("lastEventId")
+	}
+	lastEventID, _ := strconv.ParseUint(lastID, 10, 64)
+
+	sub, missed, ok := h.hub.Subscribe(userID, lastEventID)
+	defer h.hub.Unsubscribe(sub)
+
+	// Поток живёт дольше WriteTimeout сервера - снимаем дедлайн для этого запроса
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !ok {
+		// История не покрывает пропуск - пусть клиент перечитает всё
+		c.Render(-1, sse.Event{Event: "reset", Data: gin.H{}})
+	}
+	for _, e := range missed {
+		writeEvent(c, e)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, open := <-sub.C:
+			if !open {
+				return
+			}
+			writeEvent(c, e)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeEvent(c *gin.Context, e events.Event) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(e.ID, 10),
+		Event: e.Type,
+		Data:  e,
+	})
+}
@@ -0,0 +1,23 @@
+// internal/handlers/jwks.go
+package handlers
+
+import (
+	"net/http"
+	"taskflow/internal/auth"
+	"taskflow/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /.well-known/jwks.json - публичные ключи для проверки наших токенов
+// (только для RS256/EdDSA)
+func JWKS(c *gin.Context) {
+	keys, ok := auth.JWKS()
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "JWKS is not available for symmetric signing keys"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
@@ -3,10 +3,12 @@ package handlers
 
 import (
 	"net/http"
+	"taskflow/internal/middleware"
+
 	"github.com/gin-gonic/gin"
 )
 
 // GET /login - показать страницу
 func LoginPage(c *gin.Context) {
-	c.HTML(http.StatusOK, "login.html", gin.H{"status": "ok"})
+	c.HTML(http.StatusOK, "login.html", gin.H{"status": "ok", "csrfToken": middleware.CSRFToken(c)})
 }
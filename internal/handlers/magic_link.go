@@ -0,0 +1,142 @@
+// internal/handlers/magic_link.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"taskflow/internal/auth"
+	"taskflow/internal/email"
+	"taskflow/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	magicLinkTTL         = 15 * time.Minute
+	magicLinkMaxAttempts = 5
+)
+
+// POST /api/v1/login/magic {"email": "..."} - вход без пароля: ссылка и код на почту
+func (h *AuthHandler) MagicLink(c *gin.Context) {
+	var req models.MagicLinkReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	h.sendMagicLink(req.Email)
+
+	// Как и при сбросе пароля, не раскрываем, зарегистрирован ли email
+	c.JSON(http.StatusOK, gin.H{"message": "If the email is registered, a login link has been sent"})
+}
+
+// sendMagicLink выдаёт новый токен входа и отправляет письмо (если пользователь существует).
+// Неподтверждённым аккаунтам ссылку не шлём - им сначала нужно пройти /verify.
+func (h *AuthHandler) sendMagicLink(address string) {
+	user, err := h.userRepo.GetByEmail(address)
+	if err != nil || user == nil || !user.IsVerified || user.Disabled() {
+		return
+	}
+
+	code := email.GenerateCode()
+	raw, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		fmt.Printf("⚠️ Failed to generate login token: %v\n", err)
+		return
+	}
+
+	err = h.tokenRepo.Replace(&models.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   models.PurposeMagicLogin,
+		TokenHash: hash,
+		CodeHash:  auth.HashToken(code),
+		ExpiresAt: time.Now().Add(magicLinkTTL),
+	})
+	if err != nil {
+		fmt.Printf("⚠️ Failed to store login token: %v\n", err)
+		return
+	}
+
+	link := "/login?magic=" + url.QueryEscape(raw)
+	if err := h.emailService.SendMagicLink(email.UserRecipient(user), code, link); err != nil {
+		fmt.Printf("⚠️ Failed to queue login link email: %v\n", err)
+	}
+}
+
+// POST /api/v1/login/magic/verify {"token": "..."} или {"email": "...", "code": "123456"}
+func (h *AuthHandler) MagicLogin(c *gin.Context) {
+	var req models.MagicLoginReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if !allowAttempt(c, h.guard, req.Email) {
+		return
+	}
+
+	token, ok := h.findMagicToken(c, req)
+	if !ok {
+		recordFailure(c, h.guard, h.notifier, h.auditRepo, req.Email, nil)
+		h.audit(c, models.AuditAuthLogin, models.AuditFailure, 0, req.Email, "invalid login link, method="+loginMethodMagicLink)
+		return
+	}
+
+	// Одноразовость: ссылку открыли дважды - войдёт только первый запрос
+	claimed, err := h.tokenRepo.MarkUsed(token.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to log in"})
+		return
+	}
+	if !claimed {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid or expired login link"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(token.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid or expired login link"})
+		return
+	}
+
+	h.beginLogin(c, user, loginMethodMagicLink)
+}
+
+// findMagicToken ищет действующий токен входа по ссылке или по паре email + код.
+// При ошибке сам отвечает клиенту.
+func (h *AuthHandler) findMagicToken(c *gin.Context, req models.MagicLoginReq) (*models.OneTimeToken, bool) {
+	invalid := func() (*models.OneTimeToken, bool) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid or expired login link"})
+		return nil, false
+	}
+	now := time.Now()
+
+	if req.Token != "" {
+		token, err := h.tokenRepo.GetByTokenHash(models.PurposeMagicLogin, auth.HashToken(req.Token))
+		if err != nil || !token.Active(now) {
+			return invalid()
+		}
+		return token, true
+	}
+
+	if req.Email == "" || req.Code == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Token or email and code are required"})
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetByEmail(req.Email)
+	if err != nil || user == nil {
+		return invalid()
+	}
+	token, err := h.tokenRepo.GetLatest(user.ID, models.PurposeMagicLogin)
+	if err != nil || !token.Active(now) {
+		return invalid()
+	}
+
+	if !checkTokenCode(h.tokenRepo, token, req.Code, magicLinkMaxAttempts) {
+		return invalid()
+	}
+	return token, true
+}
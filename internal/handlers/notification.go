@@ -0,0 +1,227 @@
+// internal/handlers/notification.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotificationHandler struct {
+	notificationRepo *repository.NotificationRepository
+	prefRepo         *repository.NotificationPreferenceRepository
+	userRepo         *repository.UserRepository
+	notifier         *notify.Service
+}
+
+func NewNotificationHandler(
+	notificationRepo *repository.NotificationRepository,
+	prefRepo *repository.NotificationPreferenceRepository,
+	userRepo *repository.UserRepository,
+	notifier *notify.Service,
+) *NotificationHandler {
+	return &NotificationHandler{
+		notificationRepo: notificationRepo,
+		prefRepo:         prefRepo,
+		userRepo:         userRepo,
+		notifier:         notifier,
+	}
+}
+
+// GET /api/v1/notifications?unread=true&limit=20&offset=0
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := pagination(c, 20, 100)
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, total, err := h.notificationRepo.ListByUser(userID, unreadOnly, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get notifications"})
+		return
+	}
+
+	unread, err := h.notificationRepo.CountUnread(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to count notifications"})
+		return
+	}
+
+	response := make([]models.NotificationResponse, len(notifications))
+	for i, n := range notifications {
+		response[i] = toNotificationResponse(n)
+	}
+
+	c.JSON(http.StatusOK, models.NotificationsResponse{
+		Notifications: response,
+		UnreadCount:   unread,
+		Total:         total,
+	})
+}
+
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	if _, err := h.notificationRepo.MarkRead(userID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Notification marked as read"})
+}
+
+// POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.notificationRepo.MarkAllRead(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "All notifications marked as read",
+		"updated": updated,
+	})
+}
+
+// GET /api/v1/notifications/preferences
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	h.respondPreferences(c, user)
+}
+
+// PUT /api/v1/notifications/preferences {"timezone": "Europe/Moscow", "digestHour": 9, "events": {"task.completed": "off"}}
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	// Сначала валидируем всё, потом сохраняем
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Unknown timezone"})
+			return
+		}
+	}
+
+	known := make(map[models.NotificationType]bool, len(notify.EventTypes))
+	for _, t := range notify.EventTypes {
+		known[t] = true
+	}
+	for eventType, mode := range req.Events {
+		if !known[models.NotificationType(eventType)] {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Unknown event type: " + eventType})
+			return
+		}
+		if !mode.Valid() {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Delivery must be one of: immediate, digest, off"})
+			return
+		}
+	}
+
+	if req.Timezone != nil || req.DigestHour != nil {
+		if req.Timezone != nil {
+			user.Timezone = *req.Timezone
+		}
+		if req.DigestHour != nil {
+			user.DigestHour = *req.DigestHour
+		}
+		if err := h.userRepo.UpdateNotificationSettings(user.ID, user.Timezone, user.DigestHour); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save preferences"})
+			return
+		}
+	}
+
+	for eventType, mode := range req.Events {
+		if err := h.prefRepo.Upsert(user.ID, models.NotificationType(eventType), mode); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save preferences"})
+			return
+		}
+	}
+
+	h.respondPreferences(c, user)
+}
+
+func (h *NotificationHandler) respondPreferences(c *gin.Context, user *models.User) {
+	prefs, err := h.notifier.Preferences(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get preferences"})
+		return
+	}
+
+	events := make(map[string]models.DeliveryMode, len(prefs))
+	for t, mode := range prefs {
+		events[string(t)] = mode
+	}
+
+	c.JSON(http.StatusOK, models.NotificationPreferencesResponse{
+		Timezone:   user.Timezone,
+		DigestHour: user.DigestHour,
+		Events:     events,
+	})
+}
+
+func toNotificationResponse(n models.Notification) models.NotificationResponse {
+	response := models.NotificationResponse{
+		ID:        n.ID,
+		Type:      string(n.Type),
+		Title:     n.Title,
+		Body:      n.Body,
+		Link:      n.Link,
+		Read:      n.ReadAt != nil,
+		CreatedAt: n.CreatedAt.Format(time.RFC3339),
+	}
+	if n.ReadAt != nil {
+		response.ReadAt = n.ReadAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,344 @@
+// internal/handlers/oidc.go
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"taskflow/internal/auth"
+	"taskflow/internal/cookies"
+	"taskflow/internal/email"
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/oidc"
+	"taskflow/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcBasePath   = "/api/v1/oidc"
+	oidcHandoffTTL = 2 * time.Minute // от callback до POST /api/v1/oidc/exchange со страницы входа
+)
+
+var (
+	errEmailNotVerified   = errors.New("provider did not confirm the email")
+	errAccountNotVerified = errors.New("local account with this email is not verified")
+)
+
+type OIDCHandler struct {
+	providers  *oidc.Registry
+	identities *repository.ExternalIdentityRepository
+	userRepo   *repository.UserRepository
+	tokenRepo  *repository.OneTimeTokenRepository
+	jar        *cookies.Policy
+	login      *AuthHandler // вход завершается так же, как по паролю (включая 2FA)
+	notifier   *notify.Service
+}
+
+func NewOIDCHandler(
+	providers *oidc.Registry,
+	identities *repository.ExternalIdentityRepository,
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.OneTimeTokenRepository,
+	jar *cookies.Policy,
+	login *AuthHandler,
+	notifier *notify.Service,
+) *OIDCHandler {
+	return &OIDCHandler{
+		providers:  providers,
+		identities: identities,
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		jar:        jar,
+		login:      login,
+		notifier:   notifier,
+	}
+}
+
+// GET /api/v1/oidc/providers - кнопки "Войти через ..." на странице входа
+func (h *OIDCHandler) GetProviders(c *gin.Context) {
+	providers := make([]models.OIDCProviderResponse, 0, len(h.providers.List()))
+	for _, p := range h.providers.List() {
+		providers = append(providers, models.OIDCProviderResponse{
+			Name:        p.Name,
+			DisplayName: p.DisplayName,
+			LoginURL:    oidcBasePath + "/" + p.Name + "/login",
+		})
+	}
+	c.JSON(http.StatusOK, models.OIDCProvidersResponse{Providers: providers})
+}
+
+// GET /api/v1/oidc/:provider/login - редирект на страницу входа провайдера
+func (h *OIDCHandler) Login(c *gin.Context) {
+	provider, ok := h.providers.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Unknown provider"})
+		return
+	}
+
+	flow, err := h.providers.Flows.Start(provider.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to start login"})
+		return
+	}
+
+	authURL, err := provider.AuthURL(c.Request.Context(), flow)
+	if err != nil {
+		fmt.Printf("⚠️ OIDC %s: %v\n", provider.Name, err)
+		redirectLoginError(c, "provider_unavailable")
+		return
+	}
+
+	// state в cookie привязывает callback к этому браузеру (защита от подброшенного входа)
+	h.jar.Set(c, cookies.OIDCState, flow.State, time.Until(flow.ExpiresAt))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// GET /api/v1/oidc/:provider/callback?code=...&state=...
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	state := c.Query("state")
+	cookieState := h.jar.Get(c, cookies.OIDCState)
+	h.jar.Clear(c, cookies.OIDCState)
+
+	flow, ok := h.providers.Flows.Take(state)
+	if !ok || cookieState == "" || subtle.ConstantTimeCompare([]byte(cookieState), []byte(state)) != 1 ||
+		flow.Provider != c.Param("provider") {
+		redirectLoginError(c, "invalid_state")
+		return
+	}
+	provider, ok := h.providers.Get(flow.Provider)
+	if !ok {
+		redirectLoginError(c, "invalid_state")
+		return
+	}
+
+	if c.Query("error") != "" || c.Query("code") == "" {
+		redirectLoginError(c, "access_denied")
+		return
+	}
+
+	identity, err := provider.Authenticate(c.Request.Context(), c.Query("code"), flow)
+	if err != nil {
+		fmt.Printf("⚠️ OIDC %s: %v\n", provider.Name, err)
+		redirectLoginError(c, "provider_error")
+		return
+	}
+
+	user, err := h.resolveUser(c, identity)
+	switch {
+	case errors.Is(err, errEmailNotVerified):
+		redirectLoginError(c, "email_not_verified")
+		return
+	case errors.Is(err, errAccountNotVerified):
+		redirectLoginError(c, "account_not_verified")
+		return
+	case err != nil:
+		fmt.Printf("⚠️ OIDC %s: failed to link identity: %v\n", provider.Name, err)
+		redirectLoginError(c, "provider_error")
+		return
+	}
+
+	// Сессию выдаёт POST /api/v1/oidc/exchange со страницы входа:
+	// там тот же JS, что и для пароля, и тот же второй шаг 2FA
+	raw, hash, err := auth.NewOpaqueToken()
+	if err == nil {
+		err = h.tokenRepo.Replace(&models.OneTimeToken{
+			UserID:    user.ID,
+			Purpose:   models.PurposeExternalLogin,
+			TokenHash: hash,
+			ExpiresAt: time.Now().Add(oidcHandoffTTL),
+		})
+	}
+	if err != nil {
+		redirectLoginError(c, "provider_error")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/login?oidc="+url.QueryEscape(raw))
+}
+
+// resolveUser пользователь для внешнего аккаунта:
+// уже привязанный по (provider, sub), иначе существующий с тем же email, иначе новый.
+// По email связываем только подтверждённые провайдером адреса - иначе можно
+// завести у провайдера чужой email и войти в чужой аккаунт.
+func (h *OIDCHandler) resolveUser(c *gin.Context, identity *oidc.Identity) (*models.User, error) {
+	now := time.Now()
+
+	linked, err := h.identities.GetBySubject(identity.Provider, identity.Subject)
+	if err == nil {
+		if err := h.identities.TouchLogin(linked.ID, identity.Email, now); err != nil {
+			fmt.Printf("⚠️ Failed to update external identity: %v\n", err)
+		}
+		return h.userRepo.GetByID(linked.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errEmailNotVerified
+	}
+
+	user, err := h.userRepo.GetByEmail(identity.Email)
+	switch {
+	case err == nil && user != nil:
+		// Неподтверждённый аккаунт мог зарегистрировать кто угодно - не отдаём его
+		if !user.IsVerified {
+			return nil, errAccountNotVerified
+		}
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	default:
+		user, err = h.createUser(identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = h.identities.Create(&models.ExternalIdentity{
+		UserID:      user.ID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.notifier.Notify(user.ID, notify.Message{
+		Type: models.NotificationAuthSecurity,
+		Key:  notify.MsgOIDCLinked,
+		Data: map[string]interface{}{"Provider": identity.Provider, "IP": c.ClientIP()},
+	})
+	return user, nil
+}
+
+// createUser новый пользователь из внешнего аккаунта. Пароль случайный и никому не известен:
+// войти по паролю можно будет после сброса через почту.
+func (h *OIDCHandler) createUser(identity *oidc.Identity) (*models.User, error) {
+	randomPassword, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := auth.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := identity.GivenName, identity.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(identity.Name, " ")
+	}
+
+	user := &models.User{
+		Email:      identity.Email,
+		Password:   hashedPassword,
+		FirstName:  firstName,
+		LastName:   lastName,
+		IsVerified: true,
+		Locale:     email.ResolveLocale(identity.Locale),
+	}
+	if err := h.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// POST /api/v1/oidc/exchange {"token": "..."} - завершает вход после callback
+func (h *OIDCHandler) Exchange(c *gin.Context) {
+	var req models.OIDCExchangeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if !allowAttempt(c, h.login.guard, "") {
+		return
+	}
+
+	token, err := h.tokenRepo.GetByTokenHash(models.PurposeExternalLogin, auth.HashToken(req.Token))
+	if err != nil || !token.Active(time.Now()) {
+		recordFailure(c, h.login.guard, h.notifier, h.login.auditRepo, "", nil)
+		h.login.audit(c, models.AuditAuthLogin, models.AuditFailure, 0, "", "invalid handoff token, method="+loginMethodOIDC)
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid or expired login, please try again"})
+		return
+	}
+
+	claimed, err := h.tokenRepo.MarkUsed(token.ID)
+	if err != nil || !claimed {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid or expired login, please try again"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(token.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid or expired login, please try again"})
+		return
+	}
+
+	h.login.beginLogin(c, user, loginMethodOIDC)
+}
+
+// GET /api/v1/me/identities
+func (h *OIDCHandler) GetIdentities(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	identities, err := h.identities.ListByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get linked accounts"})
+		return
+	}
+
+	response := make([]models.ExternalIdentityResponse, len(identities))
+	for i, identity := range identities {
+		response[i] = models.ExternalIdentityResponse{
+			ID:        identity.ID,
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt.Format(time.RFC3339),
+		}
+		if identity.LastLoginAt != nil {
+			response[i].LastLoginAt = identity.LastLoginAt.Format(time.RFC3339)
+		}
+	}
+	c.JSON(http.StatusOK, models.ExternalIdentitiesResponse{Identities: response})
+}
+
+// DELETE /api/v1/me/identities/:id
+func (h *OIDCHandler) DeleteIdentity(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	found, err := h.identities.Delete(userID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to unlink account"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Linked account not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Account unlinked"})
+}
+
+// redirectLoginError возвращает браузер на страницу входа с кодом ошибки (текст подбирает login.js)
+func redirectLoginError(c *gin.Context, code string) {
+	c.Redirect(http.StatusFound, "/login?oidc_error="+url.QueryEscape(code))
+}
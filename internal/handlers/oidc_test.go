@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"taskflow/internal/auth"
+	"taskflow/internal/bruteforce"
+	"taskflow/internal/config"
+	"taskflow/internal/cookies"
+	"taskflow/internal/database"
+	"taskflow/internal/email"
+	"taskflow/internal/events"
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/oidc"
+	"taskflow/internal/password"
+	"taskflow/internal/repository"
+	"taskflow/internal/session"
+	"taskflow/internal/twofactor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcTestApp приложение с тестовым провайдером на одном httptest-сервере - как OIDC_STUB в разработке
+type oidcTestApp struct {
+	url string
+	jar *cookies.Policy
+}
+
+func newOIDCTestApp(t *testing.T) *oidcTestApp {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	if err := database.Open(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	err := auth.Init(config.AuthConfig{
+		JWTAlgorithm:    "HS256",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 24 * time.Hour,
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	const stubPath = "/dev/oidc"
+	provider := config.OIDCProvider{
+		Name:         "stub",
+		Issuer:       srv.URL + stubPath,
+		ClientID:     "taskflow-test",
+		ClientSecret: "test-secret",
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+	stub, err := oidc.NewStub(provider.Issuer, provider.ClientID, provider.ClientSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.Any(stubPath+"/*path", gin.WrapH(http.StripPrefix(stubPath, stub)))
+
+	userRepo := repository.NewUserRepository()
+	tokenRepo := repository.NewOneTimeTokenRepository()
+	jar := cookies.NewPolicy(config.CookieConfig{Path: "/", SameSite: "lax"})
+	sessions := session.NewManager(repository.NewSessionRepository(), repository.NewRefreshTokenRepository(), userRepo)
+	emailService := email.NewService(email.NewMemorySender(), "noreply@example.com", "", srv.URL)
+	notifier := notify.NewService(repository.NewNotificationRepository(), repository.NewNotificationPreferenceRepository(), userRepo, emailService, events.NewHub(10))
+	guard := bruteforce.NewGuard(bruteforce.DefaultAccountPolicy, bruteforce.DefaultIPPolicy)
+	authHandler := NewAuthHandler(userRepo, tokenRepo, sessions, jar,
+		twofactor.NewService(repository.NewTwoFactorRepository(), "TaskFlow"), guard, emailService, notifier,
+		repository.NewAuditRepository(), password.NewPolicy(config.PasswordConfig{MinLength: 10, MaxLength: 128}, 0), "")
+
+	registry := oidc.NewRegistry([]config.OIDCProvider{provider}, srv.URL+oidcBasePath)
+	h := NewOIDCHandler(registry, repository.NewExternalIdentityRepository(), userRepo, tokenRepo, jar, authHandler, notifier)
+	router.GET(oidcBasePath+"/:provider/login", h.Login)
+	router.GET(oidcBasePath+"/:provider/callback", h.Callback)
+	router.POST(oidcBasePath+"/exchange", h.Exchange)
+
+	return &oidcTestApp{url: srv.URL, jar: jar}
+}
+
+// browser клиент с cookie, который не ходит по редиректам сам
+func browser(t *testing.T) *http.Client {
+	t.Helper()
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{
+		Jar:           jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// get и postForm выполняют запрос и возвращают адрес редиректа
+func get(t *testing.T, client *http.Client, target string) *url.URL {
+	t.Helper()
+	resp, err := client.Get(target)
+	return redirectTarget(t, resp, err)
+}
+
+func postForm(t *testing.T, client *http.Client, target string, form url.Values) *url.URL {
+	t.Helper()
+	resp, err := client.PostForm(target, form)
+	return redirectTarget(t, resp, err)
+}
+
+func redirectTarget(t *testing.T, resp *http.Response, err error) *url.URL {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("%s %s: status %d, want 302", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode)
+	}
+	location, err := resp.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+// startLogin GET /login у приложения и вход на странице провайдера; возвращает адрес callback
+func (app *oidcTestApp) startLogin(t *testing.T, client *http.Client, userEmail string, verified bool) *url.URL {
+	t.Helper()
+	authorize := get(t, client, app.url+oidcBasePath+"/stub/login")
+	q := authorize.Query()
+
+	form := url.Values{
+		"redirect_uri":   {q.Get("redirect_uri")},
+		"state":          {q.Get("state")},
+		"nonce":          {q.Get("nonce")},
+		"code_challenge": {q.Get("code_challenge")},
+		"email":          {userEmail},
+		"name":           {"Ivan Petrov"},
+	}
+	if verified {
+		form.Set("email_verified", "true")
+	}
+	return postForm(t, client, app.url+"/dev/oidc/authorize", form)
+}
+
+// loginError код ошибки из редиректа на страницу входа
+func loginError(t *testing.T, location *url.URL) string {
+	t.Helper()
+	if location.Path != "/login" {
+		t.Fatalf("redirect to %s, want /login", location)
+	}
+	return location.Query().Get("oidc_error")
+}
+
+// exchange меняет токен передачи на сессию. С origin запрос выглядит как fetch со страницы.
+func (app *oidcTestApp) exchange(t *testing.T, client *http.Client, token, origin string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	req, err := http.NewRequest("POST", app.url+oidcBasePath+"/exchange", strings.NewReader(`{"token":"`+token+`"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	return resp, body
+}
+
+// handoff проходит вход у провайдера до редиректа с токеном передачи
+func (app *oidcTestApp) handoff(t *testing.T, client *http.Client, email string) string {
+	t.Helper()
+	callback := app.startLogin(t, client, email, true)
+	done := get(t, client, callback.String())
+	if errCode := done.Query().Get("oidc_error"); errCode != "" {
+		t.Fatalf("callback failed: %s", errCode)
+	}
+	handoff := done.Query().Get("oidc")
+	if done.Path != "/login" || handoff == "" {
+		t.Fatalf("unexpected callback redirect %s", done)
+	}
+	return handoff
+}
+
+func TestOIDCLoginCallbackExchange(t *testing.T) {
+	app := newOIDCTestApp(t)
+	client := browser(t)
+
+	handoff := app.handoff(t, client, "ivan@example.com")
+
+	resp, body := app.exchange(t, client, handoff, "")
+	if resp.StatusCode != http.StatusOK || body["token"] == "" || body["token"] == nil {
+		t.Fatalf("exchange: status %d, body %v", resp.StatusCode, body)
+	}
+
+	var user models.User
+	if err := database.DB.Where("email = ?", "ivan@example.com").First(&user).Error; err != nil {
+		t.Fatalf("user was not created: %v", err)
+	}
+	if !user.IsVerified || user.FirstName != "Ivan" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+	var linked int64
+	database.DB.Model(&models.ExternalIdentity{}).Where("user_id = ?", user.ID).Count(&linked)
+	if linked != 1 {
+		t.Fatalf("linked identities = %d, want 1", linked)
+	}
+
+	// Токен передачи одноразовый
+	if resp, _ := app.exchange(t, client, handoff, ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("second exchange: status %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestOIDCExchangeFromPageUsesCookiesOnly(t *testing.T) {
+	app := newOIDCTestApp(t)
+	client := browser(t)
+	handoff := app.handoff(t, client, "ivan@example.com")
+
+	// Странице токены в JSON не нужны: сессия живёт в HttpOnly cookie
+	resp, body := app.exchange(t, client, handoff, app.url)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("exchange: status %d, body %v", resp.StatusCode, body)
+	}
+	if _, ok := body["token"]; ok {
+		t.Errorf("access token in body: %v", body)
+	}
+	if _, ok := body["refreshToken"]; ok {
+		t.Errorf("refresh token in body: %v", body)
+	}
+
+	set := map[string]bool{}
+	for _, cookie := range resp.Cookies() {
+		set[cookie.Name] = cookie.Value != "" && cookie.HttpOnly
+	}
+	if !set[app.jar.Name(cookies.Access)] || !set[app.jar.Name(cookies.Refresh)] {
+		t.Fatalf("session cookies not set: %v", resp.Header.Values("Set-Cookie"))
+	}
+}
+
+func TestOIDCCallbackRejectsBadState(t *testing.T) {
+	app := newOIDCTestApp(t)
+
+	t.Run("tampered state", func(t *testing.T) {
+		client := browser(t)
+		callback := app.startLogin(t, client, "ivan@example.com", true)
+		q := callback.Query()
+		q.Set("state", q.Get("state")+"x")
+		callback.RawQuery = q.Encode()
+		if got := loginError(t, get(t, client, callback.String())); got != "invalid_state" {
+			t.Fatalf("oidc_error = %q, want invalid_state", got)
+		}
+	})
+
+	t.Run("callback in another browser", func(t *testing.T) {
+		// Подброшенный вход: ссылку callback открывают в браузере без cookie state
+		callback := app.startLogin(t, browser(t), "ivan@example.com", true)
+		victim := browser(t)
+		if got := loginError(t, get(t, victim, callback.String())); got != "invalid_state" {
+			t.Fatalf("oidc_error = %q, want invalid_state", got)
+		}
+	})
+
+	t.Run("replayed callback", func(t *testing.T) {
+		client := browser(t)
+		callback := app.startLogin(t, client, "ivan@example.com", true)
+		get(t, client, callback.String())
+		if got := loginError(t, get(t, client, callback.String())); got != "invalid_state" {
+			t.Fatalf("oidc_error = %q, want invalid_state", got)
+		}
+	})
+}
+
+func TestOIDCUnverifiedEmailDoesNotLinkAccount(t *testing.T) {
+	app := newOIDCTestApp(t)
+
+	owner := &models.User{Email: "owner@example.com", Password: "-", IsVerified: true}
+	if err := database.DB.Create(owner).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	client := browser(t)
+	callback := app.startLogin(t, client, "owner@example.com", false)
+	if got := loginError(t, get(t, client, callback.String())); got != "email_not_verified" {
+		t.Fatalf("oidc_error = %q, want email_not_verified", got)
+	}
+
+	var linked int64
+	database.DB.Model(&models.ExternalIdentity{}).Count(&linked)
+	if linked != 0 {
+		t.Fatalf("identity linked to %s without a verified email", owner.Email)
+	}
+}
@@ -0,0 +1,69 @@
+// internal/handlers/outbox.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"taskflow/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /api/v1/admin/emails?status=dead&to=ivan@&limit=50&offset=0 - очередь отправки писем
+// (текст писем не отдаётся: в нём коды и ссылки входа)
+func (h *AdminHandler) ListEmails(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.OutboxPending, models.OutboxSending, models.OutboxSent, models.OutboxDead:
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid status (use pending, sending, sent or dead)"})
+		return
+	}
+	limit, offset := pagination(c, 50, 200)
+
+	emails, total, err := h.outboxRepo.Search(status, strings.TrimSpace(c.Query("to")), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get emails"})
+		return
+	}
+	counts, err := h.outboxRepo.CountByStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get emails"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.OutboxEmailsResponse{
+		Emails: emails,
+		Counts: counts,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// POST /api/v1/admin/emails/:id/retry - вернуть мёртвое письмо в очередь
+func (h *AdminHandler) RetryEmail(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	retried, err := h.mailQueue.Retry(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retry email"})
+		return
+	}
+	if !retried {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Email not found or not in dead letters"})
+		return
+	}
+
+	actorID := currentAdmin(c).ID
+	recordAudit(c, h.auditRepo, models.AuditEvent{
+		Action:  models.AuditAdminEmailRetry,
+		ActorID: &actorID,
+		Details: fmt.Sprintf("email %d", id),
+	})
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Email queued for retry"})
+}
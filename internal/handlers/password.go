@@ -0,0 +1,264 @@
+// internal/handlers/password.go
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"taskflow/internal/apitoken"
+	"taskflow/internal/auth"
+	"taskflow/internal/bruteforce"
+	"taskflow/internal/cookies"
+	"taskflow/internal/email"
+	"taskflow/internal/middleware"
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/password"
+	"taskflow/internal/repository"
+	"taskflow/internal/session"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	resetTokenTTL    = 30 * time.Minute
+	resetMaxAttempts = 5 // после стольких неверных кодов нужно запрашивать новый
+)
+
+type PasswordHandler struct {
+	userRepo     *repository.UserRepository
+	tokenRepo    *repository.OneTimeTokenRepository
+	sessions     *session.Manager
+	jar          *cookies.Policy
+	apiTokens    *apitoken.Service
+	guard        *bruteforce.Guard
+	emailService *email.Service
+	notifier     *notify.Service
+	auditRepo    *repository.AuditRepository
+	policy       *password.Policy
+}
+
+func NewPasswordHandler(
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.OneTimeTokenRepository,
+	sessions *session.Manager,
+	jar *cookies.Policy,
+	apiTokens *apitoken.Service,
+	guard *bruteforce.Guard,
+	emailService *email.Service,
+	notifier *notify.Service,
+	auditRepo *repository.AuditRepository,
+	policy *password.Policy,
+) *PasswordHandler {
+	return &PasswordHandler{
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		sessions:     sessions,
+		jar:          jar,
+		apiTokens:    apiTokens,
+		guard:        guard,
+		emailService: emailService,
+		notifier:     notifier,
+		auditRepo:    auditRepo,
+		policy:       policy,
+	}
+}
+
+// GET /forgot-password - страница восстановления (?token= из письма)
+func ForgotPasswordPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "forgot_password.html", gin.H{
+		"token":     c.Query("token"),
+		"csrfToken": middleware.CSRFToken(c),
+	})
+}
+
+// GET /api/v1/password/policy - требования к новому паролю для подсказок в формах
+func (h *PasswordHandler) Policy(c *gin.Context) {
+	c.JSON(http.StatusOK, h.policy.Requirements())
+}
+
+// POST /api/v1/password/forgot {"email": "..."}
+func (h *PasswordHandler) Forgot(c *gin.Context) {
+	var req models.ForgotPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	h.sendResetCode(req.Email)
+
+	// Ответ одинаковый, есть такой пользователь или нет (не раскрываем, какие email зарегистрированы)
+	c.JSON(http.StatusOK, gin.H{"message": "If the email is registered, a reset code has been sent"})
+}
+
+// sendResetCode выдаёт новый токен сброса и отправляет письмо (если пользователь существует)
+func (h *PasswordHandler) sendResetCode(address string) {
+	user, err := h.userRepo.GetByEmail(address)
+	if err != nil || user == nil || !user.IsVerified {
+		return
+	}
+
+	code := email.GenerateCode()
+	raw, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		fmt.Printf("⚠️ Failed to generate reset token: %v\n", err)
+		return
+	}
+
+	err = h.tokenRepo.Replace(&models.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   models.PurposePasswordReset,
+		TokenHash: hash,
+		CodeHash:  auth.HashToken(code),
+		ExpiresAt: time.Now().Add(resetTokenTTL),
+	})
+	if err != nil {
+		fmt.Printf("⚠️ Failed to store reset token: %v\n", err)
+		return
+	}
+
+	link := "/forgot-password?token=" + url.QueryEscape(raw)
+	if err := h.emailService.SendPasswordReset(email.UserRecipient(user), code, link); err != nil {
+		fmt.Printf("⚠️ Failed to queue password reset email: %v\n", err)
+	}
+}
+
+// POST /api/v1/password/reset {"token": "..."} или {"email": "...", "code": "123456"} + "password"
+func (h *PasswordHandler) Reset(c *gin.Context) {
+	var req models.ResetPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if !allowAttempt(c, h.guard, req.Email) {
+		return
+	}
+
+	token, ok := h.findResetToken(c, req)
+	if !ok {
+		recordFailure(c, h.guard, h.notifier, h.auditRepo, req.Email, nil)
+		recordAuthEvent(c, h.auditRepo, models.AuditAuthPasswordReset, models.AuditFailure, 0, req.Email, "invalid or expired reset code")
+		return
+	}
+
+	// Проверяем до MarkUsed: отклонённый пароль не сжигает код из письма
+	user, err := h.userRepo.GetByID(token.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid or expired reset code"})
+		return
+	}
+	if rejectWeakPassword(c, h.policy, req.Password, user) {
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to hash password"})
+		return
+	}
+
+	// Одноразовость: два параллельных запроса с одним кодом - выиграет только первый
+	claimed, err := h.tokenRepo.MarkUsed(token.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to reset password"})
+		return
+	}
+	if !claimed {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid or expired reset code"})
+		return
+	}
+
+	if err := h.userRepo.UpdatePassword(token.UserID, hashedPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to reset password"})
+		return
+	}
+
+	// Кто бы ни знал старый пароль - все его сессии завершаются
+	if err := h.sessions.RevokeAll(token.UserID, ""); err != nil {
+		fmt.Printf("⚠️ Failed to revoke sessions after password reset: %v\n", err)
+	}
+	// Персональные токены тоже: их мог выпустить тот, кто завладел аккаунтом
+	if err := h.apiTokens.RevokeAll(token.UserID); err != nil {
+		fmt.Printf("⚠️ Failed to revoke API tokens after password reset: %v\n", err)
+	}
+	clearAuthCookies(c, h.jar)
+	recordAuthEvent(c, h.auditRepo, models.AuditAuthPasswordReset, models.AuditSuccess, token.UserID, req.Email, "all sessions and API tokens revoked")
+
+	h.notifier.Notify(token.UserID, notify.Message{
+		Type: models.NotificationAuthSecurity,
+		Key:  notify.MsgPasswordReset,
+		Data: map[string]interface{}{"IP": c.ClientIP()},
+		Link: "/forgot-password",
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Password has been reset. Please log in with the new password.",
+		"redirect": "/login",
+	})
+}
+
+// findResetToken ищет действующий токен по ссылке или по паре email + код.
+// Неверный код засчитывается как попытка. При ошибке сам отвечает клиенту.
+func (h *PasswordHandler) findResetToken(c *gin.Context, req models.ResetPasswordReq) (*models.OneTimeToken, bool) {
+	invalid := func() (*models.OneTimeToken, bool) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid or expired reset code"})
+		return nil, false
+	}
+	now := time.Now()
+
+	if req.Token != "" {
+		token, err := h.tokenRepo.GetByTokenHash(models.PurposePasswordReset, auth.HashToken(req.Token))
+		if err != nil || !token.Active(now) {
+			return invalid()
+		}
+		return token, true
+	}
+
+	if req.Email == "" || req.Code == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Token or email and code are required"})
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetByEmail(req.Email)
+	if err != nil || user == nil {
+		return invalid()
+	}
+	token, err := h.tokenRepo.GetLatest(user.ID, models.PurposePasswordReset)
+	if err != nil || !token.Active(now) {
+		return invalid()
+	}
+
+	if !checkTokenCode(h.tokenRepo, token, req.Code, resetMaxAttempts) {
+		return invalid()
+	}
+	return token, true
+}
+
+// checkTokenCode сверяет код из письма с одноразовым токеном.
+// Неверный код засчитывается как попытка; после maxAttempts токен сгорает.
+func checkTokenCode(repo *repository.OneTimeTokenRepository, token *models.OneTimeToken, code string, maxAttempts int) bool {
+	if subtle.ConstantTimeCompare([]byte(auth.HashToken(code)), []byte(token.CodeHash)) == 1 {
+		return true
+	}
+	if err := repo.AddAttempt(token.ID, maxAttempts); err != nil {
+		fmt.Printf("⚠️ Failed to count code attempt: %v\n", err)
+	}
+	return false
+}
+
+// rejectWeakPassword отвечает 400 с причинами (models.PasswordReason), если новый пароль
+// не проходит политику. user - владелец пароля: его email и имя в пароле не годятся.
+func rejectWeakPassword(c *gin.Context, policy *password.Policy, newPassword string, user *models.User) bool {
+	reasons := policy.Check(newPassword, user.Email, user.FirstName, user.LastName)
+	if len(reasons) == 0 {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, models.PasswordRejectedResponse{
+		Error:   "Password does not meet the requirements",
+		Reasons: reasons,
+	})
+	return true
+}
@@ -0,0 +1,75 @@
+// internal/handlers/realtime.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"taskflow/internal/models"
+	"taskflow/internal/realtime"
+	"taskflow/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+type RealtimeHandler struct {
+	hub           *realtime.Hub
+	userRepo      *repository.UserRepository
+	allowedOrigin string
+}
+
+func NewRealtimeHandler(hub *realtime.Hub, userRepo *repository.UserRepository, allowedOrigin string) *RealtimeHandler {
+	return &RealtimeHandler{
+		hub:           hub,
+		userRepo:      userRepo,
+		allowedOrigin: allowedOrigin,
+	}
+}
+
+// GET /api/v1/ws (Upgrade: websocket)
+func (h *RealtimeHandler) Connect(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "User not found"})
+		return
+	}
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" {
+		name = user.Email
+	}
+
+	server := websocket.Server{
+		Handshake: h.checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			h.hub.Serve(ws, userID, name)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkOrigin защищает от cross-site WebSocket hijacking: браузер шлёт
+// cookie с токеном на любой origin, поэтому пускаем только свой сайт
+func (h *RealtimeHandler) checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		// не браузер (скрипты, CLI) - cookie-атаки тут невозможны
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if origin == h.allowedOrigin || u.Host == req.Host {
+		config.Origin = u
+		return nil
+	}
+	return fmt.Errorf("origin %s not allowed", origin)
+}
@@ -0,0 +1,214 @@
+// internal/handlers/reminder.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// defaultSnooze на сколько откладываем напоминание, если длительность не указана
+const defaultSnooze = 10 * time.Minute
+
+type ReminderHandler struct {
+	reminderRepo *repository.ReminderRepository
+	taskRepo     *repository.TaskRepository
+}
+
+func NewReminderHandler(reminderRepo *repository.ReminderRepository, taskRepo *repository.TaskRepository) *ReminderHandler {
+	return &ReminderHandler{
+		reminderRepo: reminderRepo,
+		taskRepo:     taskRepo,
+	}
+}
+
+// GET /api/v1/tasks/:id/reminders
+func (h *ReminderHandler) GetReminders(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	taskID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	if _, err := h.taskRepo.GetUserTask(userID, taskID); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Task not found"})
+		return
+	}
+
+	reminders, err := h.reminderRepo.GetByTask(userID, taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get reminders"})
+		return
+	}
+
+	response := make([]models.ReminderResponse, len(reminders))
+	for i, r := range reminders {
+		response[i] = toReminderResponse(&r)
+	}
+	c.JSON(http.StatusOK, models.RemindersResponse{Reminders: response})
+}
+
+// POST /api/v1/tasks/:id/reminders {"at": "2026-01-02T10:00:00Z"} или {"beforeDue": "1h"}
+func (h *ReminderHandler) CreateReminder(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	taskID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateReminderReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if (req.At == nil) == (req.BeforeDue == "") {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Specify exactly one of 'at' or 'beforeDue'"})
+		return
+	}
+
+	task, err := h.taskRepo.GetUserTask(userID, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Task not found"})
+		return
+	}
+
+	reminder := &models.Reminder{
+		TaskID: task.ID,
+		UserID: userID,
+	}
+
+	if req.At != nil {
+		reminder.RemindAt = *req.At
+	} else {
+		offset, err := time.ParseDuration(req.BeforeDue)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid beforeDue duration"})
+			return
+		}
+		if task.DueAt == nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Task has no due date"})
+			return
+		}
+		seconds := int64(offset / time.Second)
+		reminder.OffsetSeconds = &seconds
+		reminder.RemindAt = task.DueAt.Add(-offset)
+	}
+
+	if reminder.RemindAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Reminder time is in the past"})
+		return
+	}
+
+	if err := h.reminderRepo.Create(reminder); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create reminder"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toReminderResponse(reminder))
+}
+
+// DELETE /api/v1/reminders/:id
+func (h *ReminderHandler) DeleteReminder(c *gin.Context) {
+	reminder, ok := h.getReminder(c)
+	if !ok {
+		return
+	}
+
+	if err := h.reminderRepo.Delete(reminder.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete reminder"})
+		return
+	}
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Reminder deleted successfully"})
+}
+
+// POST /api/v1/reminders/:id/snooze {"duration": "15m"}
+func (h *ReminderHandler) SnoozeReminder(c *gin.Context) {
+	reminder, ok := h.getReminder(c)
+	if !ok {
+		return
+	}
+
+	var req models.SnoozeReminderReq
+	// тело необязательное
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	snooze := defaultSnooze
+	if req.Duration != "" {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 || d > 7*24*time.Hour {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid snooze duration"})
+			return
+		}
+		snooze = d
+	}
+
+	// Отложенное напоминание становится абсолютным и снова ждёт отправки
+	reminder.RemindAt = time.Now().Add(snooze)
+	reminder.OffsetSeconds = nil
+	reminder.SentAt = nil
+	reminder.Disarmed = false
+	reminder.SnoozeCount++
+
+	if err := h.reminderRepo.Update(reminder); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to snooze reminder"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toReminderResponse(reminder))
+}
+
+func (h *ReminderHandler) getReminder(c *gin.Context) (*models.Reminder, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	reminder, err := h.reminderRepo.GetUserReminder(userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Reminder not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get reminder"})
+		}
+		return nil, false
+	}
+	return reminder, true
+}
+
+func toReminderResponse(r *models.Reminder) models.ReminderResponse {
+	response := models.ReminderResponse{
+		ID:       r.ID,
+		TaskID:   r.TaskID,
+		RemindAt: r.RemindAt.Format(time.RFC3339),
+		Sent:     r.SentAt != nil,
+		Disarmed: r.Disarmed,
+	}
+	if r.OffsetSeconds != nil {
+		response.BeforeDue = (time.Duration(*r.OffsetSeconds) * time.Second).String()
+	}
+	if r.SentAt != nil {
+		response.SentAt = r.SentAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,114 @@
+// internal/handlers/session.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"taskflow/internal/cookies"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+	"taskflow/internal/session"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessions  *session.Manager
+	jar       *cookies.Policy
+	auditRepo *repository.AuditRepository
+}
+
+func NewSessionHandler(sessions *session.Manager, jar *cookies.Policy, auditRepo *repository.AuditRepository) *SessionHandler {
+	return &SessionHandler{sessions: sessions, jar: jar, auditRepo: auditRepo}
+}
+
+// GET /api/v1/sessions
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessions.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get sessions"})
+		return
+	}
+
+	current := c.GetString("sessionID")
+	response := make([]models.SessionResponse, len(sessions))
+	for i, s := range sessions {
+		response[i] = models.SessionResponse{
+			ID:           s.ID,
+			Device:       s.Device,
+			IP:           s.IP,
+			UserAgent:    s.UserAgent,
+			CreatedAt:    s.CreatedAt.Format(time.RFC3339),
+			LastSeenAt:   s.LastSeenAt.Format(time.RFC3339),
+			Current:      s.ID == current,
+			Impersonated: s.ImpersonatorID != nil,
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SessionsResponse{Sessions: response})
+}
+
+// DELETE /api/v1/sessions/:id
+func (h *SessionHandler) DeleteSession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	found, err := h.sessions.Revoke(userID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to revoke session"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Session not found"})
+		return
+	}
+
+	recordAuthEvent(c, h.auditRepo, models.AuditAuthSessionRevoke, models.AuditSuccess, userID, c.GetString("userEmail"), "session "+id)
+
+	if id == c.GetString("sessionID") {
+		clearAuthCookies(c, h.jar)
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Session revoked"})
+}
+
+// DELETE /api/v1/sessions?keepCurrent=true - выйти на всех устройствах
+func (h *SessionHandler) DeleteAllSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	keep := ""
+	if c.Query("keepCurrent") == "true" {
+		keep = c.GetString("sessionID")
+	}
+
+	if err := h.sessions.RevokeAll(userID, keep); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to revoke sessions"})
+		return
+	}
+	fmt.Printf("🚪 User %d logged out everywhere (keep current: %t)\n", userID, keep != "")
+	recordAuthEvent(c, h.auditRepo, models.AuditAuthSessionRevoke, models.AuditSuccess, userID, c.GetString("userEmail"),
+		fmt.Sprintf("all sessions (keep current: %t)", keep != ""))
+
+	if keep == "" {
+		clearAuthCookies(c, h.jar)
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Logged out on all devices",
+			"redirect": "/login",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Other sessions revoked"})
+}
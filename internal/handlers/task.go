@@ -6,7 +6,10 @@ import (
 	"net/http"
 	"strconv"
 	"taskflow/internal/constants"
+	"taskflow/internal/events"
+	"taskflow/internal/middleware"
 	"taskflow/internal/models"
+	"taskflow/internal/notify"
 	"taskflow/internal/repository"
 	"time"
 
@@ -14,17 +17,52 @@ import (
 )
 
 type TaskHandler struct {
-	userRepo *repository.UserRepository
-	taskRepo *repository.TaskRepository
+	userRepo     *repository.UserRepository
+	taskRepo     *repository.TaskRepository
+	reminderRepo *repository.ReminderRepository
+	notifier     *notify.Service
+	hub          *events.Hub
 }
 
-func NewTaskHandler(userRepo *repository.UserRepository, taskRepo *repository.TaskRepository) *TaskHandler {
+func NewTaskHandler(
+	userRepo *repository.UserRepository,
+	taskRepo *repository.TaskRepository,
+	reminderRepo *repository.ReminderRepository,
+	notifier *notify.Service,
+	hub *events.Hub,
+) *TaskHandler {
 	return &TaskHandler{
-		userRepo: userRepo,
-		taskRepo: taskRepo,
+		userRepo:     userRepo,
+		taskRepo:     taskRepo,
+		reminderRepo: reminderRepo,
+		notifier:     notifier,
+		hub:          hub,
 	}
 }
 
+// publishTask рассылает событие об изменении задачи открытым вкладкам
+func (h *TaskHandler) publishTask(eventType string, task *models.Task) {
+	h.hub.Publish(task.UserID, eventType, models.TaskResponse{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		CreatedAt:   task.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   task.UpdatedAt.Format(time.RFC3339),
+		DueAt:       formatDueAt(task.DueAt),
+	})
+}
+
+// notifyCompleted сообщает о выполненной задаче во входящие
+func (h *TaskHandler) notifyCompleted(task *models.Task) {
+	h.notifier.Notify(task.UserID, notify.Message{
+		Type: models.NotificationTaskCompleted,
+		Key:  notify.MsgTaskCompleted,
+		Data: map[string]interface{}{"Task": task.Title},
+		Link: "/tasks",
+	})
+}
+
 func (h *TaskHandler) getUserID(c *gin.Context) (uint, bool) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -47,6 +85,14 @@ func (h *TaskHandler) getTaskID(c *gin.Context) (uint, error) {
 	return taskID, nil
 }
 
+// formatDueAt срок задачи в RFC3339 (пустая строка, если срока нет)
+func formatDueAt(dueAt *time.Time) string {
+	if dueAt == nil {
+		return ""
+	}
+	return dueAt.Format(time.RFC3339)
+}
+
 // GET /tasks
 func (h *TaskHandler) TasksPage(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -88,6 +134,7 @@ func (h *TaskHandler) TasksPage(c *gin.Context) {
 	c.HTML(http.StatusOK, "tasks.html", gin.H{
 		"FirstName": user.FirstName,
 		"LastName":  user.LastName,
+		"csrfToken": middleware.CSRFToken(c),
 	})
 }
 
@@ -114,6 +161,7 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 			Description: task.Description,
 			Completed:   task.Completed,
 			CreatedAt:   task.CreatedAt.Format(time.RFC3339),
+			DueAt:       formatDueAt(task.DueAt),
 		}
 	}
 
@@ -140,6 +188,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		Description: req.Description,
 		UserID:      userID,
 		Completed:   false,
+		DueAt:       req.DueAt,
 	}
 
 	// Сохраняем в БД
@@ -148,12 +197,15 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
+	h.publishTask(events.TaskCreated, task)
+
 	response := models.TaskResponse{
 		ID:          task.ID,
 		Title:       task.Title,
 		Description: task.Description,
 		Completed:   task.Completed,
 		CreatedAt:   task.CreatedAt.Format(time.RFC3339), // форматируем дату
+		DueAt:       formatDueAt(task.DueAt),
 	}
 
 	c.JSON(http.StatusCreated, response)
@@ -196,7 +248,22 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	if req.Description != nil {
 		task.Description = *req.Description
 	}
+	dueChanged := req.DueAt != nil
+	if req.DueAt != nil {
+		if *req.DueAt == "" {
+			task.DueAt = nil
+		} else {
+			dueAt, err := time.Parse(time.RFC3339, *req.DueAt)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid dueAt format, expected RFC3339"})
+				return
+			}
+			task.DueAt = &dueAt
+		}
+	}
+	justCompleted := false
 	if req.Completed != nil {
+		justCompleted = *req.Completed && !task.Completed
 		task.Completed = *req.Completed
 	}
 
@@ -209,6 +276,18 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	h.publishTask(events.TaskUpdated, task)
+
+	if dueChanged {
+		if err := h.reminderRepo.RescheduleRelative(task.ID, task.DueAt); err != nil {
+			fmt.Printf("⚠️ Failed to reschedule reminders: %v\n", err)
+		}
+	}
+
+	if justCompleted {
+		h.notifyCompleted(task)
+	}
+
 	// Отвечаем
 	c.JSON(http.StatusOK, models.TaskResponse{
 		ID:          task.ID,
@@ -217,6 +296,7 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		Completed:   task.Completed,
 		CreatedAt:   task.CreatedAt.Format(constants.TimeFormat),
 		UpdatedAt:   task.UpdatedAt.Format(constants.TimeFormat),
+		DueAt:       formatDueAt(task.DueAt),
 	})
 }
 
@@ -244,6 +324,11 @@ func (h *TaskHandler) ToggleTask(c *gin.Context) {
 	task.UpdatedAt = time.Now()
 
 	h.taskRepo.Update(task)
+	h.publishTask(events.TaskUpdated, task)
+
+	if task.Completed {
+		h.notifyCompleted(task)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"id":        task.ID,
@@ -278,5 +363,11 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		})
 		return
 	}
+
+	if err := h.reminderRepo.DeleteByTask(taskID); err != nil {
+		fmt.Printf("⚠️ Failed to delete task reminders: %v\n", err)
+	}
+
+	h.hub.Publish(userID, events.TaskDeleted, gin.H{"id": taskID})
 	c.JSON(http.StatusOK, models.MessageResponse{Message: "Task deleted successfully"})
 }
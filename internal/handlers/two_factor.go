@@ -0,0 +1,223 @@
+// internal/handlers/two_factor.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"taskflow/internal/auth"
+	"taskflow/internal/bruteforce"
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/repository"
+	"taskflow/internal/twofactor"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	userRepo  *repository.UserRepository
+	twoFactor *twofactor.Service
+	guard     *bruteforce.Guard
+	notifier  *notify.Service
+	auditRepo *repository.AuditRepository
+}
+
+func NewTwoFactorHandler(
+	userRepo *repository.UserRepository,
+	twoFactor *twofactor.Service,
+	guard *bruteforce.Guard,
+	notifier *notify.Service,
+	auditRepo *repository.AuditRepository,
+) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		userRepo:  userRepo,
+		twoFactor: twoFactor,
+		guard:     guard,
+		notifier:  notifier,
+		auditRepo: auditRepo,
+	}
+}
+
+// GET /api/v1/me/2fa
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	status, err := h.twoFactor.Status(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to get 2FA status"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// POST /api/v1/me/2fa/setup {"password": "..."}
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	var req models.TwoFactorSetupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+	if !h.checkPassword(c, user, req.Password, "") {
+		return
+	}
+	h.guard.Success(user.Email)
+
+	setup, err := h.twoFactor.Setup(user)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, setup)
+}
+
+// POST /api/v1/me/2fa/confirm {"code": "123456"} - включает 2FA, отдаёт коды восстановления
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var req models.TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactor.Confirm(user, req.Code)
+	if err != nil {
+		if errors.Is(err, twofactor.ErrInvalidCode) {
+			recordAuthEvent(c, h.auditRepo, models.AuditAuth2FAEnable, models.AuditFailure, user.ID, user.Email, "invalid code")
+		}
+		respondTwoFactorError(c, err)
+		return
+	}
+	recordAuthEvent(c, h.auditRepo, models.AuditAuth2FAEnable, models.AuditSuccess, user.ID, user.Email, "")
+
+	h.notifier.Notify(user.ID, notify.Message{
+		Type: models.NotificationAuthSecurity,
+		Key:  notify.MsgTwoFAEnabled,
+		Data: map[string]interface{}{"IP": c.ClientIP()},
+	})
+
+	// Коды показываются один раз - в БД только хеши
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// POST /api/v1/me/2fa/disable {"password": "...", "code": "123456"}
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req models.TwoFactorDisableReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+	if !h.checkPassword(c, user, req.Password, models.AuditAuth2FADisable) {
+		return
+	}
+	if !h.verifyCode(c, user, req.Code, models.AuditAuth2FADisable) {
+		return
+	}
+
+	if err := h.twoFactor.Disable(user); err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	recordAuthEvent(c, h.auditRepo, models.AuditAuth2FADisable, models.AuditSuccess, user.ID, user.Email, "")
+
+	h.notifier.Notify(user.ID, notify.Message{
+		Type: models.NotificationAuthSecurity,
+		Key:  notify.MsgTwoFADisabled,
+		Data: map[string]interface{}{"IP": c.ClientIP()},
+	})
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Two-factor authentication disabled"})
+}
+
+// POST /api/v1/me/2fa/recovery-codes {"code": "123456"} - новый набор кодов
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUser(c, h.userRepo)
+	if !ok {
+		return
+	}
+	if !allowAttempt(c, h.guard, user.Email) {
+		return
+	}
+	if !h.verifyCode(c, user, req.Code, models.AuditAuth2FARecoveryRegenerate) {
+		return
+	}
+
+	codes, err := h.twoFactor.RegenerateRecoveryCodes(user)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	recordAuthEvent(c, h.auditRepo, models.AuditAuth2FARecoveryRegenerate, models.AuditSuccess, user.ID, user.Email, "")
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// checkPassword проверяет пароль вошедшего пользователя под защитой от перебора:
+// украденный сеанс не должен давать подбирать пароль без задержек. false - ответ уже отправлен.
+// action - действие для журнала аудита при неверном пароле (пусто - не записывать).
+func (h *TwoFactorHandler) checkPassword(c *gin.Context, user *models.User, password, action string) bool {
+	if !allowAttempt(c, h.guard, user.Email) {
+		return false
+	}
+	if !auth.CheckPasswordHash(password, user.Password) {
+		recordFailure(c, h.guard, h.notifier, h.auditRepo, user.Email, user)
+		if action != "" {
+			recordAuthEvent(c, h.auditRepo, action, models.AuditFailure, user.ID, user.Email, "password is incorrect")
+		}
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Password is incorrect"})
+		return false
+	}
+	return true
+}
+
+// verifyCode проверяет код 2FA; неверный код засчитывается как неудачная попытка
+// и пишется в журнал аудита как неудача action
+func (h *TwoFactorHandler) verifyCode(c *gin.Context, user *models.User, code, action string) bool {
+	if err := h.twoFactor.Verify(user, code); err != nil {
+		if errors.Is(err, twofactor.ErrInvalidCode) {
+			recordFailure(c, h.guard, h.notifier, h.auditRepo, user.Email, user)
+			recordAuthEvent(c, h.auditRepo, action, models.AuditFailure, user.ID, user.Email, "invalid code")
+		}
+		respondTwoFactorError(c, err)
+		return false
+	}
+	h.guard.Success(user.Email)
+	return true
+}
+
+// respondTwoFactorError переводит ошибки twofactor.Service в HTTP-ответ
+func respondTwoFactorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid two-factor code"})
+	case errors.Is(err, twofactor.ErrNotEnabled):
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Two-factor authentication is not enabled"})
+	case errors.Is(err, twofactor.ErrAlreadyEnabled):
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Two-factor authentication is already enabled"})
+	case errors.Is(err, twofactor.ErrNotSetUp):
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Start two-factor setup first"})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Two-factor operation failed"})
+	}
+}
@@ -0,0 +1,32 @@
+// internal/middleware/admin.go
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskflow/internal/repository"
+)
+
+// RequireAdmin пускает только администраторов. Роль читается из БД на каждый запрос,
+// поэтому снятие роли действует сразу, без перевыпуска токенов.
+// Ставится после AuthMiddleware.
+func RequireAdmin(users *repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("userID")
+		if !ok {
+			unauthorized(c)
+			return
+		}
+
+		user, err := users.GetByID(userID.(uint))
+		if err != nil || !user.IsAdmin() || user.Disabled() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			return
+		}
+
+		c.Set("adminUser", user)
+		c.Next()
+	}
+}
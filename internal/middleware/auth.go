@@ -2,46 +2,100 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"taskflow/internal/apitoken"
 	"taskflow/internal/auth"
+	"taskflow/internal/cookies"
+	"taskflow/internal/session"
 )
 
-// internal/middleware/auth.go
-func AuthMiddleware() gin.HandlerFunc {
+// Откуда пришли учётные данные (c.Get("authSource"))
+const (
+	AuthSourceHeader   = "header"    // access-токен в Authorization
+	AuthSourceCookie   = "cookie"    // access-токен в cookie (браузер)
+	AuthSourceAPIToken = "api_token" // персональный токен, права ограничены scopes
+)
+
+// AuthMiddleware пускает только запросы с действующим access-токеном,
+// чья серверная сессия не завершена и сам токен не отозван,
+// либо с персональным токеном (Authorization: Bearer tf_pat_...).
+func AuthMiddleware(sessions *session.Manager, apiTokens *apitoken.Service, jar *cookies.Policy) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
+		tokenString, source := extractToken(c, jar)
 		if tokenString == "" {
-			cookie, err := c.Cookie("token")
-			if err == nil {
-				tokenString = cookie
-			}
+			unauthorized(c)
+			return
 		}
 
-		if tokenString == "" {
-			if strings.HasPrefix(c.Request.URL.Path, "/api/") {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			} else {
-				c.Redirect(http.StatusFound, "/login")
+		if apitoken.IsAPIToken(tokenString) {
+			token, err := apiTokens.Authenticate(tokenString)
+			if err != nil || source != AuthSourceHeader {
+				unauthorized(c)
+				return
 			}
-			c.Abort()
+			c.Set("userID", token.UserID)
+			c.Set("authSource", AuthSourceAPIToken)
+			c.Set("apiTokenID", token.ID)
+			c.Set("apiScopes", token.ScopeList())
+			c.Next()
 			return
 		}
 
 		claims, err := auth.ValidateToken(tokenString)
 		if err != nil {
-			// ... обработка ошибки
+			// Просрочен, подписан неизвестным (например, выведенным из ротации) ключом и т.п.
+			unauthorized(c)
 			return
 		}
 
-		fmt.Printf("🔑 Token validated: userID=%d, type=%T\n", claims.UserID, claims.UserID) // 👈 отладка
+		if err := sessions.Validate(claims, c.ClientIP()); err != nil {
+			// Logout, "выйти везде" или сессию завершили с другого устройства
+			unauthorized(c)
+			return
+		}
 
 		c.Set("userID", claims.UserID)
 		c.Set("userEmail", claims.Email)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("tokenClaims", claims)
+		c.Set("authSource", source)
+		if claims.Impersonator != 0 {
+			c.Set("impersonatorID", claims.Impersonator)
+		}
 		c.Next()
 	}
 }
+
+// ExtractToken достаёт access-токен из заголовка Authorization или cookie
+func ExtractToken(c *gin.Context, jar *cookies.Policy) string {
+	token, _ := extractToken(c, jar)
+	return token
+}
+
+// extractToken токен и откуда он взят. Заголовок: "Bearer <token>" или просто "<token>"
+func extractToken(c *gin.Context, jar *cookies.Policy) (string, string) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+			header = header[7:]
+		}
+		return strings.TrimSpace(header), AuthSourceHeader
+	}
+	if cookie := jar.Get(c, cookies.Access); cookie != "" {
+		return cookie, AuthSourceCookie
+	}
+	return "", ""
+}
+
+// unauthorized отвечает 401 для API и редиректит на логин для страниц
+func unauthorized(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, "/api/") {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	} else {
+		c.Redirect(http.StatusFound, "/login")
+	}
+	c.Abort()
+}
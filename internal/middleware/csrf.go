@@ -0,0 +1,87 @@
+// internal/middleware/csrf.go
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskflow/internal/cookies"
+)
+
+// CSRF по схеме double-submit: браузер получает токен в cookie и в <meta name="csrf-token">
+// на странице, JS возвращает его в заголовке X-CSRF-Token. Чужой сайт может заставить
+// браузер отправить cookie, но прочитать токен и подставить заголовок - нет.
+const CSRFHeader = "X-CSRF-Token"
+
+// IssueCSRFToken выдаёт браузеру CSRF-токен, если его ещё нет,
+// и кладёт его в контекст для шаблонов (см. CSRFToken)
+func IssueCSRFToken(jar *cookies.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := jar.Get(c, cookies.CSRF)
+		if token == "" {
+			var err error
+			if token, err = newCSRFToken(); err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate CSRF token"})
+				return
+			}
+			// Живёт до закрытия браузера; JS читает токен со страницы, поэтому cookie HttpOnly
+			jar.Set(c, cookies.CSRF, token, 0)
+		}
+		c.Set("csrfToken", token)
+		c.Next()
+	}
+}
+
+// CSRFToken токен текущего запроса - для <meta name="csrf-token"> в шаблонах
+func CSRFToken(c *gin.Context) string {
+	return c.GetString("csrfToken")
+}
+
+// RequireCSRF проверяет токен у изменяющих запросов, авторизованных cookie.
+// Запросы с заголовком Authorization (JS, мобильные клиенты, персональные токены)
+// браузер сам не подделает, поэтому их не трогаем.
+func RequireCSRF(jar *cookies.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if !cookieAuthenticated(c, jar) {
+			c.Next()
+			return
+		}
+
+		cookie := jar.Get(c, cookies.CSRF)
+		header := c.GetHeader(CSRFHeader)
+		if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invalid CSRF token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// cookieAuthenticated запрос опирается на cookie: после AuthMiddleware это видно по authSource,
+// на открытых маршрутах (обновление токена, выход) - по отсутствию заголовка Authorization
+func cookieAuthenticated(c *gin.Context, jar *cookies.Policy) bool {
+	if source := c.GetString("authSource"); source != "" {
+		return source == AuthSourceCookie
+	}
+	if c.GetHeader("Authorization") != "" {
+		return false
+	}
+	return jar.Get(c, cookies.Access) != "" || jar.Get(c, cookies.Refresh) != ""
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,152 @@
+// internal/middleware/ratelimit.go
+package middleware
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy лимит для группы маршрутов: Requests запросов за Window.
+// Name разделяет счётчики разных групп одного клиента.
+type RateLimitPolicy struct {
+	Name     string
+	Requests int
+	Window   time.Duration
+}
+
+// RateLimitResult решение хранилища по одному запросу
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration // через сколько лимит полностью восстановится
+	RetryAfter time.Duration // через сколько можно повторить (если не Allowed)
+}
+
+// RateLimitStore хранилище счётчиков. В памяти - для одного инстанса;
+// для нескольких инстансов можно подключить общее (например, Redis) с тем же интерфейсом.
+type RateLimitStore interface {
+	Take(key string, policy RateLimitPolicy, now time.Time) RateLimitResult
+}
+
+// RateLimit ограничивает частоту запросов клиента: пользователя, если AuthMiddleware
+// уже отработал, иначе IP. nil store или пустая политика - ограничения нет.
+func RateLimit(store RateLimitStore, policy RateLimitPolicy) gin.HandlerFunc {
+	if store == nil || policy.Requests <= 0 || policy.Window <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		result := store.Take(policy.Name+":"+clientKey(c), policy, time.Now())
+
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Requests, int(policy.Window.Seconds())))
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too many requests. Please slow down.",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimitReadWrite разные лимиты для чтения (GET/HEAD) и изменений
+func RateLimitReadWrite(store RateLimitStore, read, write RateLimitPolicy) gin.HandlerFunc {
+	readLimit := RateLimit(store, read)
+	writeLimit := RateLimit(store, write)
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			readLimit(c)
+		} else {
+			writeLimit(c)
+		}
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	if userID, ok := c.Get("userID"); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// MemoryRateLimitStore token bucket на клиента с LRU-вытеснением:
+// помним не больше maxKeys клиентов, давно не заходившие вытесняются первыми.
+// Вытеснять не страшно - у простаивающего клиента корзина всё равно полная.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	maxKeys int
+	order   *list.List // front - самый свежий
+	buckets map[string]*list.Element
+}
+
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+func NewMemoryRateLimitStore(maxKeys int) *MemoryRateLimitStore {
+	if maxKeys <= 0 {
+		maxKeys = 10000
+	}
+	return &MemoryRateLimitStore{
+		maxKeys: maxKeys,
+		order:   list.New(),
+		buckets: make(map[string]*list.Element),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, policy RateLimitPolicy, now time.Time) RateLimitResult {
+	capacity := float64(policy.Requests)
+	perSecond := capacity / policy.Window.Seconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b *bucket
+	if el, ok := s.buckets[key]; ok {
+		s.order.MoveToFront(el)
+		b = el.Value.(*bucket)
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+		b.last = now
+	} else {
+		b = &bucket{key: key, tokens: capacity, last: now}
+		s.buckets[key] = s.order.PushFront(b)
+		for s.order.Len() > s.maxKeys {
+			oldest := s.order.Back()
+			s.order.Remove(oldest)
+			delete(s.buckets, oldest.Value.(*bucket).key)
+		}
+	}
+
+	result := RateLimitResult{Allowed: b.tokens >= 1}
+	if result.Allowed {
+		b.tokens--
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / perSecond)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((capacity - b.tokens) / perSecond)
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
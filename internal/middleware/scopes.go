@@ -0,0 +1,55 @@
+// internal/middleware/scopes.go
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope для персональных токенов требует право scope.
+// Обычная сессия пользователя может всё.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authSource") != AuthSourceAPIToken {
+			c.Next()
+			return
+		}
+
+		scopes, _ := c.Get("apiScopes")
+		if list, ok := scopes.([]string); ok && slices.Contains(list, scope) {
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":         "API token lacks required scope",
+			"requiredScope": scope,
+		})
+	}
+}
+
+// RequireSession закрывает маршрут для персональных токенов
+// (управление аккаунтом, сессиями и самими токенами - только из приложения)
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authSource") == AuthSourceAPIToken {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not available for API tokens"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// DenyImpersonation закрывает маршрут для администратора, вошедшего от имени пользователя:
+// смотреть можно, а менять пароль, почту, 2FA и токены - нет
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("impersonatorID"); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not available while impersonating"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -2,7 +2,6 @@ package middleware
 
 import (
     "github.com/gin-gonic/gin"
-    "golang.org/x/time/rate"
 )
 
 // SecurityHeaders добавляет заголовки безопасности
@@ -28,21 +27,6 @@ func SecurityHeaders(isProd bool) gin.HandlerFunc {
     }
 }
 
-// RateLimiter защита от DDoS (простая)
-func RateLimiter(limit rate.Limit, burst int) gin.HandlerFunc {
-    limiter := rate.NewLimiter(limit, burst)
-
-    return func(c *gin.Context) {
-        if !limiter.Allow() {
-            c.AbortWithStatusJSON(429, gin.H{
-                "error": "Too many requests. Please slow down.",
-            })
-            return
-        }
-        c.Next()
-    }
-}
-
 // CORSMiddleware с настройками под окружение
 func CORSMiddleware(isProd bool, allowedOrigin string) gin.HandlerFunc {
     return func(c *gin.Context) {
@@ -54,7 +38,7 @@ func CORSMiddleware(isProd bool, allowedOrigin string) gin.HandlerFunc {
             }
             c.Header("Access-Control-Allow-Credentials", "true")
             c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-            c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+            c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, "+CSRFHeader)
         } else {
             // В разработке - всё разрешено
             c.Header("Access-Control-Allow-Origin", "*")
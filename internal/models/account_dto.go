@@ -0,0 +1,38 @@
+// internal/models/account_dto.go
+package models
+
+// REQUESTS
+type UpdateProfileReq struct {
+	FirstName *string `json:"firstName" binding:"omitempty,max=25"`
+	LastName  *string `json:"lastName" binding:"omitempty,max=25"`
+	Locale    *string `json:"locale" binding:"omitempty,max=8"` // язык писем: email.Locales
+}
+
+type ChangePasswordReq struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+}
+
+type ChangeEmailReq struct {
+	NewEmail string `json:"newEmail" binding:"required,email,max=50"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ConfirmEmailChangeReq struct {
+	Code string `json:"code" binding:"required,len=6"`
+}
+
+// RESPONSES
+type ProfileResponse struct {
+	ID         uint   `json:"id"`
+	Email      string `json:"email"`
+	FirstName  string `json:"firstName"`
+	LastName   string `json:"lastName"`
+	IsVerified bool   `json:"isVerified"`
+	TwoFactor  bool   `json:"twoFactorEnabled"`
+	Role       string `json:"role"`
+	Timezone   string `json:"timezone"`
+	Locale     string `json:"locale"`
+	DigestHour int    `json:"digestHour"`
+	CreatedAt  string `json:"createdAt"`
+}
@@ -0,0 +1,26 @@
+// internal/models/admin.go
+package models
+
+type AdminUserResponse struct {
+	ID         uint   `json:"id"`
+	Email      string `json:"email"`
+	FirstName  string `json:"firstName"`
+	LastName   string `json:"lastName"`
+	Role       string `json:"role"`
+	IsVerified bool   `json:"isVerified"`
+	TwoFactor  bool   `json:"twoFactorEnabled"`
+	Disabled   bool   `json:"disabled"`
+	DisabledAt string `json:"disabledAt,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type AdminUsersResponse struct {
+	Users  []AdminUserResponse `json:"users"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+type SetRoleReq struct {
+	Role string `json:"role" binding:"required,oneof=user admin"`
+}
@@ -0,0 +1,70 @@
+// internal/models/api_token.go
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Права персональных токенов
+const (
+	ScopeTasksRead          = "tasks:read"
+	ScopeTasksWrite         = "tasks:write"
+	ScopeNotificationsRead  = "notifications:read"
+	ScopeNotificationsWrite = "notifications:write"
+)
+
+// APIScopes все известные права
+var APIScopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeNotificationsRead, ScopeNotificationsWrite}
+
+// APIToken персональный токен для скриптов и интеграций.
+// Сам токен показывается один раз при создании, в БД - только хеш.
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"index;not null"`
+	Name       string     `gorm:"size:100;not null"`
+	Prefix     string     `gorm:"size:16"` // начало токена, чтобы пользователь узнал его в списке
+	TokenHash  string     `gorm:"size:64;uniqueIndex;not null"`
+	Scopes     string     `gorm:"size:255"` // через пробел
+	ExpiresAt  *time.Time // nil - бессрочный
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// ScopeList права токена списком
+func (t *APIToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// Active токен не отозван и не истёк
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// REQUESTS
+type CreateAPITokenReq struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expiresInDays" binding:"min=0,max=365"` // 0 - бессрочный
+}
+
+// RESPONSES
+type APITokenResponse struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+}
+
+type CreatedAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token"` // показывается только сейчас
+}
+
+type APITokensResponse struct {
+	Tokens []APITokenResponse `json:"tokens"`
+}
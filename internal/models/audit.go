@@ -0,0 +1,63 @@
+// internal/models/audit.go
+package models
+
+import "time"
+
+// Действия в журнале аудита
+const (
+	AuditAdminUserDisable       = "admin.user.disable"
+	AuditAdminUserEnable        = "admin.user.enable"
+	AuditAdminUserVerify        = "admin.user.verify"
+	AuditAdminUserPasswordReset = "admin.user.password_reset"
+	AuditAdminUserDelete        = "admin.user.delete"
+	AuditAdminUserRole          = "admin.user.role"
+	AuditAdminImpersonate       = "admin.impersonate"
+	AuditAdminAuditExport       = "admin.audit.export"
+	AuditAdminEmailRetry        = "admin.email.retry"
+)
+
+// Вход и учётные данные
+const (
+	AuditAuthLogin          = "auth.login"
+	AuditAuthLogout         = "auth.logout"
+	AuditAuthVerify         = "auth.verify"
+	AuditAuthResendCode     = "auth.resend_code"
+	AuditAuthPasswordChange = "auth.password.change"
+	AuditAuthPasswordReset  = "auth.password.reset"
+	AuditAuthSessionRevoke  = "auth.session.revoke"
+	AuditAuthRefreshReuse   = "auth.refresh.reuse" // повторное использование refresh-токена, семейство отозвано
+	AuditAuthAPITokenRevoke = "auth.api_token.revoke"
+	AuditAuthLockout        = "auth.lockout" // аккаунт или IP заблокирован после неудачных попыток
+
+	AuditAuth2FAEnable             = "auth.2fa.enable"
+	AuditAuth2FADisable            = "auth.2fa.disable"
+	AuditAuth2FARecoveryRegenerate = "auth.2fa.recovery_regenerate"
+)
+
+// Итог действия
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEvent запись журнала безопасности. Журнал только дополняется:
+// записи не меняются и не удаляются через API (старые удаляет только AUDIT_RETENTION).
+type AuditEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdAt" gorm:"index"`
+	Action    string    `json:"action" gorm:"size:64;index;not null"`
+	Outcome   string    `json:"outcome" gorm:"size:16;not null"`
+	UserID    *uint     `json:"userId,omitempty" gorm:"index"`         // чей аккаунт затронут
+	ActorID   *uint     `json:"actorId,omitempty" gorm:"index"`        // кто действовал, если не сам пользователь (администратор)
+	Email     string    `json:"email,omitempty" gorm:"size:255;index"` // с каким email пытались войти (в т.ч. несуществующим)
+	IP        string    `json:"ip" gorm:"size:64"`
+	UserAgent string    `json:"userAgent" gorm:"size:500"`
+	Details   string    `json:"details,omitempty" gorm:"size:1000"`
+}
+
+type AuditEventsResponse struct {
+	Events []AuditEvent `json:"events"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
@@ -5,7 +5,8 @@ type RegisterReq struct {
 	FirstName string `json:"firstName" binding:"max=25"`
 	LastName  string `json:"lastName" binding:"max=25"`
 	Email     string `json:"email" binding:"required,email,max=50"`
-	Password  string `json:"password" binding:"required,min=6,max=30"`
+	Password  string `json:"password" binding:"required"` // длина и стойкость - по password.Policy
+	Locale    string `json:"locale" binding:"max=8"`      // язык писем; пусто - по Accept-Language
 }
 
 type LoginReq struct {
@@ -27,3 +28,26 @@ type UserResponse struct {
 	LastName  *string `json:"lastName,omitempty"`
 	Email     string  `json:"email"`
 }
+
+type ForgotPasswordReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordReq либо token из ссылки, либо email + code из письма
+type ResetPasswordReq struct {
+	Token    string `json:"token"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Code     string `json:"code" binding:"omitempty,len=6"`
+	Password string `json:"password" binding:"required"`
+}
+
+type MagicLinkReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// MagicLoginReq либо token из ссылки, либо email + code из письма
+type MagicLoginReq struct {
+	Token string `json:"token"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Code  string `json:"code" binding:"omitempty,len=6"`
+}
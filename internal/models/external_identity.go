@@ -0,0 +1,43 @@
+// internal/models/external_identity.go
+package models
+
+import "time"
+
+// ExternalIdentity аккаунт у внешнего провайдера (OIDC), привязанный к пользователю.
+// Subject - неизменный ID пользователя у провайдера (claim "sub"), email может меняться.
+type ExternalIdentity struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"index;not null"`
+	Provider    string `gorm:"size:64;not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject     string `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject"`
+	Email       string `gorm:"size:255"` // адрес на момент последнего входа, для отображения
+	LastLoginAt *time.Time
+	CreatedAt   time.Time
+}
+
+// OIDCExchangeReq одноразовый токен из редиректа /login?oidc=...
+type OIDCExchangeReq struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type OIDCProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	LoginURL    string `json:"loginUrl"`
+}
+
+type OIDCProvidersResponse struct {
+	Providers []OIDCProviderResponse `json:"providers"`
+}
+
+type ExternalIdentityResponse struct {
+	ID          uint   `json:"id"`
+	Provider    string `json:"provider"`
+	Email       string `json:"email"`
+	LastLoginAt string `json:"lastLoginAt,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type ExternalIdentitiesResponse struct {
+	Identities []ExternalIdentityResponse `json:"identities"`
+}
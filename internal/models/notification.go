@@ -0,0 +1,47 @@
+// internal/models/notification.go
+package models
+
+import "time"
+
+// NotificationType тип события, о котором уведомляем пользователя
+type NotificationType string
+
+const (
+	NotificationTaskCreated   NotificationType = "task.created"
+	NotificationTaskUpdated   NotificationType = "task.updated"
+	NotificationTaskCompleted NotificationType = "task.completed"
+	NotificationTaskDeleted   NotificationType = "task.deleted"
+	NotificationAuthLogin     NotificationType = "auth.login"
+	NotificationAuthWelcome   NotificationType = "auth.welcome"
+	NotificationAuthSecurity  NotificationType = "auth.security" // смена пароля, почты и т.п.
+	NotificationReminder      NotificationType = "reminder"
+)
+
+type Notification struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	UserID    uint             `json:"userId" gorm:"index;not null"`
+	Type      NotificationType `json:"type" gorm:"size:50;not null"`
+	Title     string           `json:"title" gorm:"size:200;not null"`
+	Body      string           `json:"body" gorm:"type:text"`
+	Link      string           `json:"link" gorm:"size:500"`
+	ReadAt    *time.Time       `json:"readAt"`
+	CreatedAt time.Time        `json:"createdAt" gorm:"index"`
+}
+
+// Для ответа API (DTO)
+type NotificationResponse struct {
+	ID        uint   `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Link      string `json:"link,omitempty"`
+	Read      bool   `json:"read"`
+	CreatedAt string `json:"createdAt"`
+	ReadAt    string `json:"readAt,omitempty"`
+}
+
+type NotificationsResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	UnreadCount   int64                  `json:"unreadCount"`
+	Total         int64                  `json:"total"`
+}
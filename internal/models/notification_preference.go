@@ -0,0 +1,43 @@
+// internal/models/notification_preference.go
+package models
+
+import "time"
+
+// DeliveryMode как доставлять уведомления определённого типа на почту
+type DeliveryMode string
+
+const (
+	DeliveryImmediate DeliveryMode = "immediate" // письмо сразу
+	DeliveryDigest    DeliveryMode = "digest"    // попадёт в ежедневную сводку
+	DeliveryOff       DeliveryMode = "off"       // только во входящих
+)
+
+// Valid проверяет, что режим один из известных
+func (m DeliveryMode) Valid() bool {
+	switch m {
+	case DeliveryImmediate, DeliveryDigest, DeliveryOff:
+		return true
+	}
+	return false
+}
+
+type NotificationPreference struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	UserID    uint             `json:"userId" gorm:"uniqueIndex:idx_pref_user_event;not null"`
+	EventType NotificationType `json:"eventType" gorm:"uniqueIndex:idx_pref_user_event;size:50;not null"`
+	Delivery  DeliveryMode     `json:"delivery" gorm:"size:20;not null"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// REQUESTS / RESPONSES
+type NotificationPreferencesResponse struct {
+	Timezone   string                  `json:"timezone"`
+	DigestHour int                     `json:"digestHour"`
+	Events     map[string]DeliveryMode `json:"events"`
+}
+
+type UpdateNotificationPreferencesReq struct {
+	Timezone   *string                 `json:"timezone,omitempty"`
+	DigestHour *int                    `json:"digestHour,omitempty" binding:"omitempty,min=0,max=23"`
+	Events     map[string]DeliveryMode `json:"events,omitempty"`
+}
@@ -0,0 +1,35 @@
+// internal/models/one_time_token.go
+package models
+
+import "time"
+
+// TokenPurpose для чего выдан одноразовый токен
+type TokenPurpose string
+
+const (
+	PurposePasswordReset TokenPurpose = "password_reset"
+	PurposeEmailChange   TokenPurpose = "email_change" // Payload - новый адрес
+	PurposeMagicLogin    TokenPurpose = "magic_login"
+	PurposeExternalLogin TokenPurpose = "external_login" // передача входа через OIDC из callback на страницу /login
+)
+
+// OneTimeToken одноразовый секрет, отправленный пользователю письмом:
+// ссылка (TokenHash) и/или короткий код (CodeHash). В БД только хеши.
+// У пользователя одновременно действует не больше одного токена каждого назначения.
+type OneTimeToken struct {
+	ID        uint         `gorm:"primaryKey"`
+	UserID    uint         `gorm:"index;not null"`
+	Purpose   TokenPurpose `gorm:"size:32;index;not null"`
+	TokenHash string       `gorm:"size:64;index"`
+	CodeHash  string       `gorm:"size:64"`
+	Payload   string       `gorm:"size:255"`  // данные для конкретного сценария
+	Attempts  int          `gorm:"default:0"` // неверные попытки ввода кода
+	ExpiresAt time.Time    `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// Active токен ещё можно использовать
+func (t *OneTimeToken) Active(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,40 @@
+// internal/models/outbox.go
+package models
+
+import "time"
+
+// Состояние письма в очереди отправки
+const (
+	OutboxPending = "pending" // ждёт отправки (в том числе повторной)
+	OutboxSending = "sending" // взято обработчиком
+	OutboxSent    = "sent"
+	OutboxDead    = "dead" // попытки кончились или ошибка неустранима - только ручной повтор
+)
+
+// OutboxEmail письмо в очереди отправки. Пишется в базу до отправки,
+// поэтому сбой почтового сервера или перезапуск не теряют письма.
+type OutboxEmail struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	IdempotencyKey string     `json:"-" gorm:"size:64;uniqueIndex;not null"` // sha256 ключа: одно и то же письмо не ставится дважды
+	From           string     `json:"from" gorm:"size:255"`
+	To             string     `json:"to" gorm:"size:1000;index"` // адреса через запятую
+	Subject        string     `json:"subject" gorm:"size:255"`
+	HTML           string     `json:"-" gorm:"type:text"` // после отправки стирается: в письмах коды и ссылки входа
+	Text           string     `json:"-" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"size:16;index:idx_outbox_due,priority:1;not null"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt" gorm:"index:idx_outbox_due,priority:2"`
+	LockedUntil    *time.Time `json:"-"` // обработчик, взявший письмо, упал - после этого времени его возьмёт другой
+	LastError      string     `json:"lastError,omitempty" gorm:"size:1000"`
+	SentAt         *time.Time `json:"sentAt,omitempty"`
+}
+
+type OutboxEmailsResponse struct {
+	Emails []OutboxEmail    `json:"emails"`
+	Counts map[string]int64 `json:"counts"` // сколько писем в каждом состоянии
+	Total  int64            `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
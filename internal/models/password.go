@@ -0,0 +1,24 @@
+// internal/models/password.go
+package models
+
+// PasswordReason почему новый пароль не принят. Code стабилен (по нему UI показывает текст),
+// Message - для клиентов без локализации.
+type PasswordReason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Limit   int    `json:"limit,omitempty"` // минимальная/максимальная длина или требуемая оценка стойкости
+	Score   *int   `json:"score,omitempty"` // оценка пароля 0..4 (для too_weak)
+}
+
+// PasswordRejectedResponse ответ 400, если пароль не проходит политику
+type PasswordRejectedResponse struct {
+	Error   string           `json:"error"`
+	Reasons []PasswordReason `json:"reasons"`
+}
+
+// PasswordPolicyResponse требования к паролю для подсказок в форме
+type PasswordPolicyResponse struct {
+	MinLength int `json:"minLength"`
+	MaxLength int `json:"maxLength"`
+	MinScore  int `json:"minScore"` // 0..4, как у zxcvbn
+}
@@ -0,0 +1,24 @@
+// internal/models/refresh_token.go
+package models
+
+import "time"
+
+// RefreshToken долгоживущий токен для получения новых access-токенов.
+// В БД хранится только SHA-256 хеш. Все токены, полученные ротацией
+// из одного логина, образуют семейство (FamilyID): повторное
+// использование уже обменянного токена (позже нескольких секунд
+// после обмена) отзывает всё семейство.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"userId" gorm:"index;not null"`
+	FamilyID  string     `json:"familyId" gorm:"size:64;index;not null"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"not null"`
+	UsedAt    *time.Time `json:"usedAt"` // когда обменян на следующий
+	RevokedAt *time.Time `json:"revokedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refreshToken"` // можно не передавать, если есть cookie
+}
@@ -0,0 +1,45 @@
+// internal/models/reminder.go
+package models
+
+import "time"
+
+// Reminder напоминание о задаче. Либо абсолютное время, либо смещение
+// относительно срока задачи (OffsetSeconds) - тогда RemindAt пересчитывается
+// при изменении срока.
+type Reminder struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	TaskID        uint       `json:"taskId" gorm:"index;not null"`
+	UserID        uint       `json:"userId" gorm:"index;not null"`
+	RemindAt      time.Time  `json:"remindAt" gorm:"index;not null"`
+	OffsetSeconds *int64     `json:"offsetSeconds"` // за сколько секунд до срока
+	SentAt        *time.Time `json:"sentAt" gorm:"index"`
+	Disarmed      bool       `json:"disarmed" gorm:"default:false"` // у задачи убрали срок: ждёт нового срока
+	SnoozeCount   int        `json:"snoozeCount" gorm:"default:0"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// REQUESTS
+type CreateReminderReq struct {
+	At        *time.Time `json:"at,omitempty"`        // абсолютное время (RFC3339)
+	BeforeDue string     `json:"beforeDue,omitempty"` // например "30m", "24h"
+}
+
+type SnoozeReminderReq struct {
+	Duration string `json:"duration,omitempty"` // по умолчанию 10m
+}
+
+// RESPONSES
+type ReminderResponse struct {
+	ID        uint   `json:"id"`
+	TaskID    uint   `json:"taskId"`
+	RemindAt  string `json:"remindAt"`
+	BeforeDue string `json:"beforeDue,omitempty"`
+	Sent      bool   `json:"sent"`
+	Disarmed  bool   `json:"disarmed,omitempty"`
+	SentAt    string `json:"sentAt,omitempty"`
+}
+
+type RemindersResponse struct {
+	Reminders []ReminderResponse `json:"reminders"`
+}
@@ -0,0 +1,46 @@
+// internal/models/session.go
+package models
+
+import "time"
+
+// Session один вход пользователя (устройство). ID совпадает с FamilyID
+// refresh-токенов, выданных в рамках этой сессии, и лежит в claim "sid"
+// каждого access-токена.
+type Session struct {
+	ID         string     `json:"id" gorm:"primaryKey;size:32"`
+	UserID     uint       `json:"userId" gorm:"index;not null"`
+	Device     string     `json:"device" gorm:"size:100"`
+	IP         string     `json:"ip" gorm:"size:64"`
+	UserAgent  string     `json:"userAgent" gorm:"size:500"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt" gorm:"index"`
+	// ImpersonatorID администратор, вошедший от имени пользователя
+	ImpersonatorID *uint     `json:"impersonatorId,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// RevokedToken отозванный до истечения access-токен (по jti).
+// Записи можно удалять после ExpiresAt - токен всё равно уже недействителен.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;size:32"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// Для ответа API (DTO)
+type SessionResponse struct {
+	ID         string `json:"id"`
+	Device     string `json:"device"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"userAgent"`
+	CreatedAt  string `json:"createdAt"`
+	LastSeenAt string `json:"lastSeenAt"`
+	Current    bool   `json:"current"`
+	// Impersonated сессию открыл администратор от имени пользователя
+	Impersonated bool `json:"impersonated,omitempty"`
+}
+
+type SessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
@@ -4,13 +4,14 @@ package models
 import "time"
 
 type Task struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Title       string    `json:"title" gorm:"size:200;not null"`
-	Description string    `json:"description" gorm:"type:text"`
-	Completed   bool      `json:"completed" gorm:"default:false"`
-	UserID      uint      `json:"userId" gorm:"index;not null"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Title       string     `json:"title" gorm:"size:200;not null"`
+	Description string     `json:"description" gorm:"type:text"`
+	Completed   bool       `json:"completed" gorm:"default:false"`
+	UserID      uint       `json:"userId" gorm:"index;not null"`
+	DueAt       *time.Time `json:"dueAt" gorm:"index"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
 }
 
 // Для ответа API (DTO)
@@ -22,6 +23,7 @@ type TaskResponse struct {
 	CreatedAt   string `json:"createdAt"`
 	UpdatedAt   string `json:"updatedAt"`
 	CompletedAt string `json:"completedAt"`
+	DueAt       string `json:"dueAt,omitempty"`
 }
 
 type TasksResponse struct {
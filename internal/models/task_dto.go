@@ -1,13 +1,17 @@
 package models
 
+import "time"
+
 type CreateTaskReq struct {
 	Title       string `json:"title" binding:"required,min=1,max=200"`
 	Description string `json:"description" binding:"max=1000"`
+	DueAt       *time.Time `json:"dueAt,omitempty"`
 }
 
 type UpdateTaskReq struct {
     Title       *string `json:"title,omitempty" binding:"omitempty,min=3,max=200"`
     Description *string `json:"description,omitempty"`
     Completed   *bool   `json:"completed,omitempty"`
+    DueAt       *string `json:"dueAt,omitempty"` // RFC3339, пустая строка - убрать срок
 }
 
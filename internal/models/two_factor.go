@@ -0,0 +1,47 @@
+// internal/models/two_factor.go
+package models
+
+import "time"
+
+// RecoveryCode одноразовый код входа без телефона. Хранится только хеш.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// REQUESTS
+type TwoFactorSetupReq struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type TwoFactorCodeReq struct {
+	Code string `json:"code" binding:"required,max=20"` // TOTP или код восстановления
+}
+
+type TwoFactorDisableReq struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required,max=20"`
+}
+
+type TwoFactorLoginReq struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required,max=20"`
+}
+
+// RESPONSES
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"` // содержимое QR-кода
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recoveryCodesLeft"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
@@ -3,6 +3,15 @@ package models
 
 import "time"
 
+// Роли пользователей
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// MaxVerifyAttempts сколько раз можно ошибиться с кодом подтверждения email
+const MaxVerifyAttempts = 5
+
 type User struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
 	Email       string    `json:"email" gorm:"uniqueIndex;not null"`
@@ -12,6 +21,33 @@ type User struct {
 	IsVerified  bool      `json:"isVerified" gorm:"default:false"`
 	VerifyCode  string    `json:"-"`
 	CodeExpires time.Time `json:"-"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	// Неверные попытки ввода кода; после MaxVerifyAttempts код сгорает
+	VerifyAttempts int `json:"-" gorm:"default:0"`
+
+	// Настройки уведомлений
+	Timezone     string     `json:"timezone" gorm:"size:64;default:UTC"`
+	Locale       string     `json:"locale" gorm:"size:8;default:ru"` // язык писем
+	DigestHour   int        `json:"digestHour" gorm:"default:9"`     // час (по локальному времени), когда слать сводку
+	LastDigestAt *time.Time `json:"-"`
+
+	// Двухфакторная аутентификация (TOTP). Секрет задан, но не включён - настройка не подтверждена
+	TOTPSecret   string `json:"-" gorm:"size:64"`
+	TOTPEnabled  bool   `json:"totpEnabled" gorm:"default:false"`
+	TOTPLastStep int64  `json:"-"` // последний принятый шаг - код нельзя использовать повторно
+
+	Role       string     `json:"role" gorm:"size:16;default:user;not null"`
+	DisabledAt *time.Time `json:"disabledAt,omitempty"` // заблокирован администратором: войти нельзя
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// IsAdmin пользователь - администратор
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// Disabled аккаунт заблокирован администратором
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
 }
@@ -0,0 +1,100 @@
+// internal/notify/digest.go
+package notify
+
+import (
+	"time"
+
+	"taskflow/internal/email"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+)
+
+const digestTimeFormat = "02.01.2006 15:04"
+
+// DigestBuilder собирает ежедневную сводку: просроченные задачи,
+// задачи со сроком в ближайшие сутки и накопившиеся уведомления,
+// для которых выбран режим "digest".
+type DigestBuilder struct {
+	notifier *Service
+	repo     *repository.NotificationRepository
+	taskRepo *repository.TaskRepository
+}
+
+func NewDigestBuilder(notifier *Service, repo *repository.NotificationRepository, taskRepo *repository.TaskRepository) *DigestBuilder {
+	return &DigestBuilder{
+		notifier: notifier,
+		repo:     repo,
+		taskRepo: taskRepo,
+	}
+}
+
+// Build строит сводку для пользователя на момент now
+func (b *DigestBuilder) Build(user *models.User, now time.Time) (*email.Digest, error) {
+	loc := userLocation(user)
+
+	digest := &email.Digest{}
+
+	overdue, err := b.taskRepo.GetOverdue(user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	digest.Overdue = toDigestTasks(overdue, loc)
+
+	dueSoon, err := b.taskRepo.GetDueBetween(user.ID, now, now.Add(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	digest.DueSoon = toDigestTasks(dueSoon, loc)
+
+	prefs, err := b.notifier.Preferences(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var digestTypes []models.NotificationType
+	for t, mode := range prefs {
+		if mode == models.DeliveryDigest {
+			digestTypes = append(digestTypes, t)
+		}
+	}
+
+	since := now.Add(-24 * time.Hour)
+	if user.LastDigestAt != nil {
+		since = *user.LastDigestAt
+	}
+	activity, err := b.repo.ListSince(user.ID, since, digestTypes)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range activity {
+		digest.Activity = append(digest.Activity, email.DigestActivity{
+			Title: n.Title,
+			Body:  n.Body,
+			At:    n.CreatedAt.In(loc).Format(digestTimeFormat),
+		})
+	}
+
+	return digest, nil
+}
+
+func toDigestTasks(tasks []models.Task, loc *time.Location) []email.DigestTask {
+	result := make([]email.DigestTask, 0, len(tasks))
+	for _, t := range tasks {
+		result = append(result, email.DigestTask{
+			Title: t.Title,
+			DueAt: t.DueAt.In(loc).Format(digestTimeFormat),
+		})
+	}
+	return result
+}
+
+// userLocation часовой пояс пользователя (UTC, если не задан или битый)
+func userLocation(user *models.User) *time.Location {
+	if user.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
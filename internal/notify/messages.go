@@ -0,0 +1,106 @@
+// internal/notify/messages.go
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"taskflow/internal/email"
+)
+
+// Ключи текстов уведомлений. Заголовок и текст выбираются по языку получателя
+// (models.User.Locale) и становятся записью во входящих и темой/текстом письма.
+const (
+	MsgLogin          = "login"
+	MsgWelcome        = "welcome"
+	MsgTaskCompleted  = "task_completed"
+	MsgReminder       = "reminder"
+	MsgPasswordChange = "password_change"
+	MsgPasswordReset  = "password_reset"
+	MsgEmailChanged   = "email_changed"
+	MsgEmailChangedTo = "email_changed_to" // письмо на старый адрес
+	MsgTwoFAEnabled   = "2fa_enabled"
+	MsgTwoFADisabled  = "2fa_disabled"
+	MsgOIDCLinked     = "oidc_linked"
+	MsgLoginLocked    = "login_locked"
+)
+
+// messageText шаблоны заголовка и текста (text/template, поля - Message.Data)
+type messageText struct {
+	title, body string
+}
+
+// messageCatalog язык -> ключ -> текст. Языки те же, что у писем (email.Locales).
+var messageCatalog = map[string]map[string]messageText{
+	"ru": {
+		MsgLogin:          {"Новый вход в аккаунт", "IP: {{.IP}}, устройство: {{.Device}}"},
+		MsgWelcome:        {"Добро пожаловать в TaskFlow!", "Ваш email подтверждён. Создайте первую задачу."},
+		MsgTaskCompleted:  {"Задача выполнена", "{{.Task}}"},
+		MsgReminder:       {"Напоминание: {{.Task}}", `{{.Task}}{{with .Due}} (срок: {{.UTC.Format "02.01.2006 15:04"}} UTC){{end}}`},
+		MsgPasswordChange: {"Пароль изменён", "Пароль аккаунта изменён (IP: {{.IP}}). Остальные сеансы завершены."},
+		MsgPasswordReset:  {"Пароль изменён", "Пароль сброшен по ссылке из письма (IP: {{.IP}}). Все сеансы завершены. Если это были не вы, срочно восстановите доступ."},
+		MsgEmailChanged:   {"Email изменён", "Адрес {{.OldEmail}} заменён на {{.NewEmail}}."},
+		MsgEmailChangedTo: {"Email аккаунта изменён", "Email аккаунта TaskFlow изменён на {{.NewEmail}}. Если это были не вы, восстановите доступ через сброс пароля."},
+		MsgTwoFAEnabled:   {"Двухфакторная аутентификация включена", "Для входа теперь нужен код из приложения (IP: {{.IP}})."},
+		MsgTwoFADisabled:  {"Двухфакторная аутентификация выключена", "Вход снова только по паролю (IP: {{.IP}}). Если это были не вы, смените пароль."},
+		MsgOIDCLinked:     {"Подключён вход через внешний сервис", "К аккаунту привязан вход через {{.Provider}} (IP: {{.IP}}). Если это были не вы, отвяжите его в настройках и смените пароль."},
+		MsgLoginLocked:    {"Вход временно заблокирован", `Слишком много неудачных попыток входа (последняя с IP {{.IP}}). Вход заблокирован до {{.Until.UTC.Format "15:04"}} UTC. Если это были не вы, смените пароль.`},
+	},
+	"en": {
+		MsgLogin:          {"New sign-in to your account", "IP: {{.IP}}, device: {{.Device}}"},
+		MsgWelcome:        {"Welcome to TaskFlow!", "Your email is verified. Create your first task."},
+		MsgTaskCompleted:  {"Task completed", "{{.Task}}"},
+		MsgReminder:       {"Reminder: {{.Task}}", `{{.Task}}{{with .Due}} (due: {{.UTC.Format "Jan 2, 2006 15:04"}} UTC){{end}}`},
+		MsgPasswordChange: {"Password changed", "Your account password was changed (IP: {{.IP}}). All other sessions were signed out."},
+		MsgPasswordReset:  {"Password changed", "Your password was reset with an emailed link (IP: {{.IP}}). All sessions were signed out. If this wasn't you, recover your account now."},
+		MsgEmailChanged:   {"Email changed", "{{.OldEmail}} was replaced with {{.NewEmail}}."},
+		MsgEmailChangedTo: {"Your account email was changed", "Your TaskFlow account email was changed to {{.NewEmail}}. If this wasn't you, recover access with a password reset."},
+		MsgTwoFAEnabled:   {"Two-factor authentication enabled", "Signing in now requires a code from your authenticator app (IP: {{.IP}})."},
+		MsgTwoFADisabled:  {"Two-factor authentication disabled", "Signing in requires only your password again (IP: {{.IP}}). If this wasn't you, change your password."},
+		MsgOIDCLinked:     {"External sign-in connected", "Sign-in with {{.Provider}} was linked to your account (IP: {{.IP}}). If this wasn't you, unlink it in settings and change your password."},
+		MsgLoginLocked:    {"Sign-in temporarily locked", `Too many failed sign-in attempts (the last one from IP {{.IP}}). Sign-in is locked until {{.Until.UTC.Format "15:04"}} UTC. If this wasn't you, change your password.`},
+	},
+}
+
+type messageTemplate struct {
+	title, body *template.Template
+}
+
+// messages разобранный каталог. Как и шаблоны писем, разбирается при старте: ошибка - паника.
+var messages = loadMessages()
+
+func loadMessages() map[string]map[string]messageTemplate {
+	result := make(map[string]map[string]messageTemplate, len(messageCatalog))
+	for locale, texts := range messageCatalog {
+		result[locale] = make(map[string]messageTemplate, len(texts))
+		for key, text := range texts {
+			result[locale][key] = messageTemplate{
+				title: template.Must(template.New(locale + "/" + key + ".title").Option("missingkey=error").Parse(text.title)),
+				body:  template.Must(template.New(locale + "/" + key + ".body").Option("missingkey=error").Parse(text.body)),
+			}
+		}
+	}
+	return result
+}
+
+// Render заголовок и текст уведомления на языке получателя
+func Render(locale string, msg Message) (title, body string, err error) {
+	if !email.SupportedLocale(locale) {
+		locale = email.DefaultLocale
+	}
+	tmpl, ok := messages[locale][msg.Key]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification message %q", msg.Key)
+	}
+
+	var titleBuf, bodyBuf bytes.Buffer
+	if err := tmpl.title.Execute(&titleBuf, msg.Data); err != nil {
+		return "", "", fmt.Errorf("render %s/%s title: %w", locale, msg.Key, err)
+	}
+	if err := tmpl.body.Execute(&bodyBuf, msg.Data); err != nil {
+		return "", "", fmt.Errorf("render %s/%s body: %w", locale, msg.Key, err)
+	}
+	return strings.TrimSpace(titleBuf.String()), strings.TrimSpace(bodyBuf.String()), nil
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"taskflow/internal/email"
+)
+
+func TestMessageCatalogCoversLocales(t *testing.T) {
+	due := time.Date(2026, 3, 9, 14, 30, 0, 0, time.UTC)
+	data := map[string]interface{}{
+		"IP":       "203.0.113.7",
+		"Device":   "Firefox",
+		"Task":     "Написать отчёт",
+		"Due":      &due,
+		"OldEmail": "old@example.com",
+		"NewEmail": "new@example.com",
+		"Provider": "google",
+		"Until":    due,
+	}
+
+	for _, locale := range email.Locales {
+		if len(messageCatalog[locale]) != len(messageCatalog[email.DefaultLocale]) {
+			t.Errorf("%s: %d messages, want %d", locale, len(messageCatalog[locale]), len(messageCatalog[email.DefaultLocale]))
+		}
+		for key := range messageCatalog[email.DefaultLocale] {
+			title, body, err := Render(locale, Message{Key: key, Data: data})
+			if err != nil {
+				t.Errorf("%s/%s: %v", locale, key, err)
+				continue
+			}
+			if title == "" || body == "" {
+				t.Errorf("%s/%s: empty title %q or body %q", locale, key, title, body)
+			}
+		}
+	}
+}
+
+func TestRenderUsesRecipientLocale(t *testing.T) {
+	due := time.Date(2026, 3, 9, 14, 30, 0, 0, time.UTC)
+	msg := Message{Key: MsgReminder, Data: map[string]interface{}{"Task": "Report", "Due": &due}}
+
+	tests := []struct {
+		locale, title, body string
+	}{
+		{"en", "Reminder: Report", "Report (due: Mar 9, 2026 14:30 UTC)"},
+		{"ru", "Напоминание: Report", "Report (срок: 09.03.2026 14:30 UTC)"},
+		{"", "Напоминание: Report", "Report (срок: 09.03.2026 14:30 UTC)"},
+		{"de", "Напоминание: Report", "Report (срок: 09.03.2026 14:30 UTC)"},
+	}
+	for _, tt := range tests {
+		title, body, err := Render(tt.locale, msg)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.locale, err)
+		}
+		if title != tt.title || body != tt.body {
+			t.Errorf("%q: got %q / %q, want %q / %q", tt.locale, title, body, tt.title, tt.body)
+		}
+	}
+
+	// Без срока - только название задачи
+	var noDue *time.Time
+	msg.Data["Due"] = noDue
+	if _, body, _ := Render("en", msg); body != "Report" {
+		t.Errorf("no due date: body %q", body)
+	}
+
+	// Забытая подстановка - ошибка, а не "<no value>" в письме
+	if _, _, err := Render("en", Message{Key: MsgLogin}); err == nil || !strings.Contains(err.Error(), "en/login") {
+		t.Errorf("missing data: err = %v", err)
+	}
+}
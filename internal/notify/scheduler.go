@@ -0,0 +1,98 @@
+// internal/notify/scheduler.go
+package notify
+
+import (
+	"context"
+	"time"
+
+	"taskflow/internal/email"
+	"taskflow/internal/repository"
+
+	prettyprint "taskflow/pkg/pretty_print"
+
+	_ "time/tzdata" // база часовых поясов внутри бинарника (в контейнере её может не быть)
+)
+
+// DigestScheduler раз в interval проверяет, у кого из пользователей
+// наступил выбранный час сводки по его локальному времени, и отправляет её.
+type DigestScheduler struct {
+	builder      *DigestBuilder
+	userRepo     *repository.UserRepository
+	emailService *email.Service
+	interval     time.Duration
+}
+
+func NewDigestScheduler(builder *DigestBuilder, userRepo *repository.UserRepository, emailService *email.Service) *DigestScheduler {
+	return &DigestScheduler{
+		builder:      builder,
+		userRepo:     userRepo,
+		emailService: emailService,
+		interval:     time.Minute,
+	}
+}
+
+// Run блокируется до отмены ctx
+func (s *DigestScheduler) Run(ctx context.Context) {
+	prettyprint.Info("Digest scheduler started")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			prettyprint.Info("Digest scheduler stopped")
+			return
+		case now := <-ticker.C:
+			s.tick(now)
+		}
+	}
+}
+
+func (s *DigestScheduler) tick(now time.Time) {
+	users, err := s.userRepo.ListVerified()
+	if err != nil {
+		prettyprint.Error("Digest scheduler: failed to list users: %v", err)
+		return
+	}
+
+	for i := range users {
+		user := &users[i]
+		if !digestDue(user.DigestHour, user.LastDigestAt, now.In(userLocation(user))) {
+			continue
+		}
+
+		digest, err := s.builder.Build(user, now)
+		if err != nil {
+			prettyprint.Error("Digest scheduler: failed to build digest for user %d: %v", user.ID, err)
+			continue
+		}
+
+		if !digest.IsEmpty() {
+			if err := s.emailService.SendDigest(email.UserRecipient(user), digest); err != nil {
+				prettyprint.Error("Digest scheduler: failed to send digest to user %d: %v", user.ID, err)
+				continue
+			}
+			prettyprint.Debug("Digest sent to user %d", user.ID)
+		}
+
+		// Отмечаем даже пустую сводку, чтобы не пересобирать её каждую минуту
+		if err := s.userRepo.SetLastDigestAt(user.ID, now); err != nil {
+			prettyprint.Error("Digest scheduler: failed to update user %d: %v", user.ID, err)
+		}
+	}
+}
+
+// digestDue наступил ли час сводки и не отправляли ли её уже сегодня
+// (localNow - текущее время в часовом поясе пользователя)
+func digestDue(digestHour int, lastDigestAt *time.Time, localNow time.Time) bool {
+	if localNow.Hour() != digestHour {
+		return false
+	}
+	if lastDigestAt == nil {
+		return true
+	}
+
+	slotStart := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), digestHour, 0, 0, 0, localNow.Location())
+	return lastDigestAt.Before(slotStart)
+}
@@ -0,0 +1,152 @@
+// internal/notify/service.go
+package notify
+
+import (
+	"time"
+
+	"taskflow/internal/email"
+	"taskflow/internal/events"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// Message то, что хендлеры передают в сервис уведомлений.
+// Текст берётся из каталога (messages.go) по Key на языке получателя.
+type Message struct {
+	Type models.NotificationType
+	Key  string
+	Data map[string]interface{} // подстановки для текста
+	Link string
+}
+
+// EventTypes все типы событий, для которых можно настроить доставку
+var EventTypes = []models.NotificationType{
+	models.NotificationTaskCreated,
+	models.NotificationTaskUpdated,
+	models.NotificationTaskCompleted,
+	models.NotificationTaskDeleted,
+	models.NotificationAuthLogin,
+	models.NotificationAuthWelcome,
+	models.NotificationAuthSecurity,
+	models.NotificationReminder,
+}
+
+// DefaultDelivery режим доставки, если пользователь ничего не настраивал
+func DefaultDelivery(t models.NotificationType) models.DeliveryMode {
+	switch t {
+	case models.NotificationAuthLogin, models.NotificationAuthSecurity, models.NotificationReminder:
+		return models.DeliveryImmediate
+	case models.NotificationAuthWelcome:
+		// приветственное письмо и так уходит после верификации
+		return models.DeliveryOff
+	default:
+		return models.DeliveryDigest
+	}
+}
+
+type Service struct {
+	repo         *repository.NotificationRepository
+	prefRepo     *repository.NotificationPreferenceRepository
+	userRepo     *repository.UserRepository
+	emailService *email.Service
+	hub          *events.Hub
+}
+
+func NewService(
+	repo *repository.NotificationRepository,
+	prefRepo *repository.NotificationPreferenceRepository,
+	userRepo *repository.UserRepository,
+	emailService *email.Service,
+	hub *events.Hub,
+) *Service {
+	return &Service{
+		repo:         repo,
+		prefRepo:     prefRepo,
+		userRepo:     userRepo,
+		emailService: emailService,
+		hub:          hub,
+	}
+}
+
+// Notify кладёт уведомление во входящие пользователя и, если он так
+// настроил, сразу отправляет письмо.
+// Ошибки только логируются: уведомление не должно ломать основной запрос.
+func (s *Service) Notify(userID uint, msg Message) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		prettyprint.Error("Failed to load user %d for notification: %v", userID, err)
+		return
+	}
+
+	title, body, err := Render(user.Locale, msg)
+	if err != nil {
+		prettyprint.Error("Failed to render notification for user %d: %v", userID, err)
+		return
+	}
+
+	n := &models.Notification{
+		UserID: userID,
+		Type:   msg.Type,
+		Title:  title,
+		Body:   body,
+		Link:   msg.Link,
+	}
+
+	if err := s.repo.Create(n); err != nil {
+		prettyprint.Error("Failed to store notification for user %d: %v", userID, err)
+		return
+	}
+
+	prettyprint.Debug("Notification %q queued for user %d", msg.Type, userID)
+
+	s.hub.Publish(userID, events.NotificationCreated, models.NotificationResponse{
+		ID:        n.ID,
+		Type:      string(n.Type),
+		Title:     n.Title,
+		Body:      n.Body,
+		Link:      n.Link,
+		CreatedAt: n.CreatedAt.Format(time.RFC3339),
+	})
+
+	if s.Delivery(userID, msg.Type) == models.DeliveryImmediate {
+		go s.sendImmediate(user, n)
+	}
+}
+
+// Delivery режим доставки события для пользователя с учётом умолчаний
+func (s *Service) Delivery(userID uint, t models.NotificationType) models.DeliveryMode {
+	pref, err := s.prefRepo.Get(userID, t)
+	if err != nil {
+		prettyprint.Warn("Failed to load notification preference: %v", err)
+	}
+	if pref != nil {
+		return pref.Delivery
+	}
+	return DefaultDelivery(t)
+}
+
+// Preferences полная карта настроек пользователя (с подставленными умолчаниями)
+func (s *Service) Preferences(userID uint) (map[models.NotificationType]models.DeliveryMode, error) {
+	stored, err := s.prefRepo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[models.NotificationType]models.DeliveryMode, len(EventTypes))
+	for _, t := range EventTypes {
+		if mode, ok := stored[t]; ok {
+			result[t] = mode
+		} else {
+			result[t] = DefaultDelivery(t)
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) sendImmediate(user *models.User, n *models.Notification) {
+	if err := s.emailService.SendNotification(email.UserRecipient(user), n.Title, n.Body, n.Link); err != nil {
+		prettyprint.Error("Failed to send notification email: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// internal/oidc/flow.go
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"sync"
+	"time"
+)
+
+// flowTTL сколько ждём возвращения пользователя от провайдера
+const flowTTL = 10 * time.Minute
+
+// Flow незавершённый вход: живёт от редиректа к провайдеру до callback.
+// State связывает callback с браузером, Nonce - ID token с этим входом,
+// Verifier - секрет PKCE, провайдер видит только его хеш.
+type Flow struct {
+	Provider  string
+	State     string
+	Nonce     string
+	Verifier  string
+	ExpiresAt time.Time
+}
+
+// FlowStore незавершённые входы в памяти, ключ - state. Каждый flow используется один раз.
+type FlowStore struct {
+	mu    sync.Mutex
+	flows map[string]*Flow
+}
+
+func NewFlowStore() *FlowStore {
+	return &FlowStore{flows: make(map[string]*Flow)}
+}
+
+// Start создаёт новый flow для провайдера
+func (s *FlowStore) Start(provider string) (*Flow, error) {
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	flow := &Flow{
+		Provider:  provider,
+		State:     state,
+		Nonce:     nonce,
+		Verifier:  verifier,
+		ExpiresAt: now.Add(flowTTL),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Брошенные входы выметаем здесь же - отдельный воркер ради этого не нужен
+	for key, f := range s.flows {
+		if now.After(f.ExpiresAt) {
+			delete(s.flows, key)
+		}
+	}
+	s.flows[state] = flow
+	return flow, nil
+}
+
+// Take забирает flow по state (повторный callback с тем же state не пройдёт)
+func (s *FlowStore) Take(state string) (*Flow, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flow, ok := s.flows[state]
+	if !ok {
+		return nil, false
+	}
+	delete(s.flows, state)
+	if time.Now().After(flow.ExpiresAt) {
+		return nil, false
+	}
+	return flow, true
+}
+
+// challengeS256 code_challenge для PKCE (RFC 7636)
+func challengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// randomString 256 бит случайности в base64url (подходит и как PKCE verifier)
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
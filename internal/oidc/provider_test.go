@@ -0,0 +1,253 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"taskflow/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "taskflow-test"
+	testClientSecret = "test-secret"
+)
+
+// newTestProvider тестовый провайдер на httptest-сервере и клиент для него
+func newTestProvider(t *testing.T) (*Stub, *Provider) {
+	t.Helper()
+	var stub *Stub
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	var err error
+	stub, err = NewStub(srv.URL, testClientID, testClientSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := NewProvider(config.OIDCProvider{
+		Name:         "stub",
+		Issuer:       srv.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		Scopes:       []string{"email", "profile"},
+	}, "http://app.test/api/v1/oidc/stub/callback")
+	return stub, provider
+}
+
+// signIn проходит страницу входа провайдера и возвращает параметры callback
+func signIn(t *testing.T, authURL, email string, verified bool) url.Values {
+	t.Helper()
+	auth, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := auth.Query()
+
+	resp, err := http.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET authorize: status %d", resp.StatusCode)
+	}
+
+	form := url.Values{
+		"redirect_uri":   {q.Get("redirect_uri")},
+		"state":          {q.Get("state")},
+		"nonce":          {q.Get("nonce")},
+		"code_challenge": {q.Get("code_challenge")},
+		"email":          {email},
+		"name":           {"Ivan Petrov"},
+	}
+	if verified {
+		form.Set("email_verified", "true")
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err = client.PostForm(auth.Scheme+"://"+auth.Host+"/authorize", form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("POST authorize: status %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location.Query()
+}
+
+func TestAuthenticateThroughStub(t *testing.T) {
+	_, provider := newTestProvider(t)
+	ctx := context.Background()
+	flows := NewFlowStore()
+
+	flow, err := flows.Start(provider.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := provider.AuthURL(ctx, flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callback := signIn(t, authURL, "ivan@example.com", true)
+	if callback.Get("state") != flow.State {
+		t.Fatalf("state = %q, want %q", callback.Get("state"), flow.State)
+	}
+	taken, ok := flows.Take(callback.Get("state"))
+	if !ok {
+		t.Fatal("flow not found by state")
+	}
+	if _, ok := flows.Take(callback.Get("state")); ok {
+		t.Fatal("flow can be taken twice")
+	}
+
+	identity, err := provider.Authenticate(ctx, callback.Get("code"), taken)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if identity.Email != "ivan@example.com" || !identity.EmailVerified || identity.Subject == "" {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+	if identity.GivenName != "Ivan" || identity.FamilyName != "Petrov" {
+		t.Fatalf("names = %q %q", identity.GivenName, identity.FamilyName)
+	}
+
+	// Код одноразовый
+	if _, err := provider.Authenticate(ctx, callback.Get("code"), taken); !errors.Is(err, ErrExchange) {
+		t.Fatalf("reused code: err = %v, want ErrExchange", err)
+	}
+}
+
+func TestAuthenticateRejectsWrongVerifier(t *testing.T) {
+	_, provider := newTestProvider(t)
+	ctx := context.Background()
+
+	flow, err := NewFlowStore().Start(provider.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := provider.AuthURL(ctx, flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback := signIn(t, authURL, "ivan@example.com", true)
+
+	stolen := *flow
+	stolen.Verifier = "attacker-does-not-know-the-verifier"
+	if _, err := provider.Authenticate(ctx, callback.Get("code"), &stolen); !errors.Is(err, ErrExchange) {
+		t.Fatalf("err = %v, want ErrExchange", err)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	stub, provider := newTestProvider(t)
+	ctx := context.Background()
+	const nonce = "expected-nonce"
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := func() jwt.MapClaims {
+		now := time.Now()
+		return jwt.MapClaims{
+			"iss":            stub.issuer,
+			"sub":            "subject-1",
+			"aud":            testClientID,
+			"iat":            now.Unix(),
+			"exp":            now.Add(5 * time.Minute).Unix(),
+			"nonce":          nonce,
+			"email":          "ivan@example.com",
+			"email_verified": true,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(jwt.MapClaims)
+		key    *rsa.PrivateKey
+		ok     bool
+	}{
+		{name: "valid", modify: func(jwt.MapClaims) {}, ok: true},
+		{name: "azp matches", modify: func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other-client"}
+			c["azp"] = testClientID
+		}, ok: true},
+		{name: "wrong nonce", modify: func(c jwt.MapClaims) { c["nonce"] = "other-nonce" }},
+		{name: "missing nonce", modify: func(c jwt.MapClaims) { delete(c, "nonce") }},
+		{name: "wrong aud", modify: func(c jwt.MapClaims) { c["aud"] = "other-client" }},
+		{name: "several aud without azp", modify: func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other-client"} }},
+		{name: "azp of another client", modify: func(c jwt.MapClaims) { c["azp"] = "other-client" }},
+		{name: "wrong iss", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "expired", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "no exp", modify: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "no sub", modify: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "foreign signing key", modify: func(jwt.MapClaims) {}, key: otherKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(claims)
+			key := stub.key
+			if tt.key != nil {
+				key = tt.key
+			}
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+			token.Header["kid"] = stub.kid
+			raw, err := token.SignedString(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			identity, err := provider.verifyIDToken(ctx, raw, nonce)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if identity.Subject != "subject-1" {
+					t.Fatalf("subject = %q", identity.Subject)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsUnsignedToken(t *testing.T) {
+	stub, provider := newTestProvider(t)
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss":   stub.issuer,
+		"sub":   "subject-1",
+		"aud":   testClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": "n",
+	})
+	raw, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.verifyIDToken(context.Background(), raw, "n"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("err = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,43 @@
+// internal/oidc/registry.go
+package oidc
+
+import (
+	"strings"
+	"taskflow/internal/config"
+)
+
+// Registry настроенные провайдеры и общее хранилище незавершённых входов
+type Registry struct {
+	Flows     *FlowStore
+	providers map[string]*Provider
+	order     []*Provider
+}
+
+// NewRegistry callbackBase - внешний адрес, к которому добавляется "/{name}/callback"
+func NewRegistry(providers []config.OIDCProvider, callbackBase string) *Registry {
+	r := &Registry{
+		Flows:     NewFlowStore(),
+		providers: make(map[string]*Provider, len(providers)),
+	}
+	callbackBase = strings.TrimRight(callbackBase, "/")
+
+	for _, cfg := range providers {
+		if _, exists := r.providers[cfg.Name]; exists {
+			continue
+		}
+		p := NewProvider(cfg, callbackBase+"/"+cfg.Name+"/callback")
+		r.providers[cfg.Name] = p
+		r.order = append(r.order, p)
+	}
+	return r
+}
+
+func (r *Registry) Get(name string) (*Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// List провайдеры в порядке из конфигурации (для кнопок на странице входа)
+func (r *Registry) List() []*Provider {
+	return r.order
+}
@@ -0,0 +1,270 @@
+// internal/oidc/stub.go
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const stubCodeTTL = time.Minute
+
+// Stub минимальный OIDC-провайдер для локальной разработки: discovery, authorize,
+// token (с проверкой PKCE) и JWKS. Паролей нет - на странице входа просто вводится email.
+// Монтируется как http.Handler с обрезанным префиксом issuer.
+type Stub struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	key          *rsa.PrivateKey
+	kid          string
+
+	mu     sync.Mutex
+	grants map[string]stubGrant // выданные authorization code
+	mux    *http.ServeMux
+}
+
+type stubGrant struct {
+	redirectURI string
+	nonce       string
+	challenge   string
+	email       string
+	name        string
+	verified    bool
+	expiresAt   time.Time
+}
+
+// NewStub ключ подписи генерируется при каждом запуске
+func NewStub(issuer, clientID, clientSecret string) (*Stub, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Stub{
+		issuer:       strings.TrimRight(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		key:          key,
+		kid:          kid[:8],
+		grants:       make(map[string]stubGrant),
+		mux:          http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	s.mux.HandleFunc("GET /authorize", s.authorizeForm)
+	s.mux.HandleFunc("POST /authorize", s.authorize)
+	s.mux.HandleFunc("POST /token", s.token)
+	s.mux.HandleFunc("GET /jwks", s.jwks)
+	return s, nil
+}
+
+func (s *Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Stub) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/authorize",
+		"token_endpoint":                        s.issuer + "/token",
+		"jwks_uri":                              s.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+var stubLoginPage = template.Must(template.New("stub").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head><meta charset="UTF-8"><title>Test IdP</title></head>
+<body style="font-family: Arial, sans-serif; max-width: 400px; margin: 40px auto;">
+	<h2>Тестовый провайдер входа</h2>
+	<p>Только для разработки: пароль не нужен.</p>
+	<form method="POST" action="{{.Action}}">
+		{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">{{end}}
+		<p><label>Email<br><input type="email" name="email" required></label></p>
+		<p><label>Имя<br><input type="text" name="name"></label></p>
+		<p><label><input type="checkbox" name="email_verified" value="true" checked> Email подтверждён</label></p>
+		<p><button type="submit">Войти</button> <button type="submit" name="deny" value="1" formnovalidate>Отказать</button></p>
+	</form>
+</body>
+</html>`))
+
+// GET /authorize - страница входа провайдера
+func (s *Stub) authorizeForm(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != s.clientID || q.Get("redirect_uri") == "" {
+		http.Error(w, "unknown client or missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "only response_type=code with PKCE S256 is supported", http.StatusBadRequest)
+		return
+	}
+
+	params := map[string]string{}
+	for _, name := range []string{"redirect_uri", "state", "nonce", "code_challenge"} {
+		params[name] = q.Get(name)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	stubLoginPage.Execute(w, map[string]interface{}{"Action": s.issuer + "/authorize", "Params": params})
+}
+
+// POST /authorize - выдаёт code и возвращает пользователя на redirect_uri
+func (s *Stub) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad form", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(r.PostForm.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "bad redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	query := redirect.Query()
+	query.Set("state", r.PostForm.Get("state"))
+
+	if r.PostForm.Get("deny") != "" {
+		query.Set("error", "access_denied")
+	} else {
+		code, err := randomString()
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		s.mu.Lock()
+		s.grants[code] = stubGrant{
+			redirectURI: redirect.String(),
+			nonce:       r.PostForm.Get("nonce"),
+			challenge:   r.PostForm.Get("code_challenge"),
+			email:       strings.TrimSpace(r.PostForm.Get("email")),
+			name:        strings.TrimSpace(r.PostForm.Get("name")),
+			verified:    r.PostForm.Get("email_verified") == "true",
+			expiresAt:   time.Now().Add(stubCodeTTL),
+		}
+		s.mu.Unlock()
+		query.Set("code", code)
+	}
+
+	redirect.RawQuery = query.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// POST /token - обмен code на ID token
+func (s *Stub) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.clientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.clientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	grant, found := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	if !found || time.Now().After(grant.expiresAt) ||
+		grant.redirectURI != r.PostForm.Get("redirect_uri") ||
+		challengeS256(r.PostForm.Get("code_verifier")) != grant.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	subject := sha256.Sum256([]byte(strings.ToLower(grant.email)))
+	claims := jwt.MapClaims{
+		"iss":            s.issuer,
+		"sub":            hex.EncodeToString(subject[:8]),
+		"aud":            s.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          grant.nonce,
+		"email":          grant.email,
+		"email_verified": grant.verified,
+	}
+	if grant.name != "" {
+		claims["name"] = grant.name
+		given, family, _ := strings.Cut(grant.name, " ")
+		claims["given_name"] = given
+		claims["family_name"] = family
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = s.kid
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	accessToken, _ := randomString()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+// GET /jwks
+func (s *Stub) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": s.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
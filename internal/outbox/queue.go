@@ -0,0 +1,245 @@
+// internal/outbox/queue.go
+package outbox
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"taskflow/internal/config"
+	"taskflow/internal/email"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// Queue очередь отправки писем. Для email.Service это обычный email.Sender:
+// Send только сохраняет письмо в базе, а доставляют его обработчики Run через настоящий транспорт.
+type Queue struct {
+	repo   *repository.OutboxRepository
+	sender email.Sender
+	cfg    config.OutboxConfig
+	wake   chan struct{}
+}
+
+func NewQueue(repo *repository.OutboxRepository, sender email.Sender, cfg config.OutboxConfig) *Queue {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	return &Queue{
+		repo:   repo,
+		sender: sender,
+		cfg:    cfg,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Send ставит письмо в очередь. Повтор письма с тем же IdempotencyKey молча пропускается.
+func (q *Queue) Send(msg *email.Message) error {
+	key, err := idempotencyKey(msg.IdempotencyKey)
+	if err != nil {
+		return err
+	}
+
+	created, err := q.repo.Enqueue(&models.OutboxEmail{
+		IdempotencyKey: key,
+		From:           msg.From,
+		To:             strings.Join(msg.To, ", "),
+		Subject:        msg.Subject,
+		HTML:           msg.HTML,
+		Text:           msg.Text,
+		Status:         models.OutboxPending,
+		NextAttemptAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		prettyprint.Debug("Email %q to %s is already queued, skipping duplicate", msg.Subject, strings.Join(msg.To, ", "))
+		return nil
+	}
+
+	q.notify()
+	return nil
+}
+
+// Retry возвращает мёртвое письмо в очередь (false - письма нет или оно не мёртвое)
+func (q *Queue) Retry(id uint) (bool, error) {
+	ok, err := q.repo.Retry(id)
+	if ok {
+		q.notify()
+	}
+	return ok, err
+}
+
+// notify будит диспетчер, не дожидаясь PollInterval
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run раздаёт письма обработчикам, пока не отменён ctx. При остановке новые письма
+// не берутся, а уже начатые отправки дожидаются - Run возвращается после них.
+func (q *Queue) Run(ctx context.Context) {
+	jobs := make(chan models.OutboxEmail)
+	var wg sync.WaitGroup
+	for i := 0; i < q.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for outboxEmail := range jobs {
+				q.deliver(outboxEmail)
+			}
+		}()
+	}
+	defer func() {
+		close(jobs)
+		wg.Wait()
+		prettyprint.Info("Email outbox stopped")
+	}()
+
+	poll := time.NewTicker(q.cfg.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	q.purgeSent(time.Now())
+
+	for {
+		q.dispatch(ctx, jobs)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+		case <-q.wake:
+		case now := <-cleanup.C:
+			q.purgeSent(now)
+		}
+	}
+}
+
+// dispatch берёт письма, которым пора уходить, пачками по числу обработчиков
+func (q *Queue) dispatch(ctx context.Context, jobs chan<- models.OutboxEmail) {
+	for ctx.Err() == nil {
+		claimed, err := q.repo.ClaimDue(time.Now(), q.cfg.Workers, q.cfg.Lease)
+		if err != nil {
+			prettyprint.Error("Failed to claim queued emails: %v", err)
+			return
+		}
+		if len(claimed) == 0 {
+			return
+		}
+
+		for i := range claimed {
+			select {
+			case jobs <- claimed[i]:
+			case <-ctx.Done():
+				// Остановка: не доставшиеся обработчикам письма возвращаем в очередь сразу,
+				// а не через Lease
+				for _, rest := range claimed[i:] {
+					if err := q.repo.Release(rest.ID); err != nil {
+						prettyprint.Error("Failed to release queued email %d: %v", rest.ID, err)
+					}
+				}
+				return
+			}
+		}
+	}
+}
+
+// deliver одна попытка отправки; неудача - повтор позже или мёртвое письмо
+func (q *Queue) deliver(outboxEmail models.OutboxEmail) {
+	attempts := outboxEmail.Attempts + 1
+
+	err := q.sender.Send(&email.Message{
+		From:    outboxEmail.From,
+		To:      splitRecipients(outboxEmail.To),
+		Subject: outboxEmail.Subject,
+		HTML:    outboxEmail.HTML,
+		Text:    outboxEmail.Text,
+	})
+	if err == nil {
+		if err := q.repo.MarkSent(outboxEmail.ID, attempts); err != nil {
+			prettyprint.Error("Failed to mark email %d as sent: %v", outboxEmail.ID, err)
+		}
+		prettyprint.Debug("📧 Email %d sent to %s", outboxEmail.ID, outboxEmail.To)
+		return
+	}
+
+	dead := email.IsPermanent(err) || attempts >= q.cfg.MaxAttempts
+	delay := q.backoff(attempts)
+	if err := q.repo.MarkFailed(outboxEmail.ID, attempts, err.Error(), time.Now().Add(delay), dead); err != nil {
+		prettyprint.Error("Failed to record email %d failure: %v", outboxEmail.ID, err)
+	}
+
+	if dead {
+		prettyprint.Error("Email %d to %s moved to dead letters after %d attempts: %v", outboxEmail.ID, outboxEmail.To, attempts, err)
+		return
+	}
+	prettyprint.Warn("Email %d to %s failed (attempt %d), retry in %s: %v", outboxEmail.ID, outboxEmail.To, attempts, delay.Round(time.Second), err)
+}
+
+// backoff пауза перед следующей попыткой: BaseDelay * 2^(attempts-1), не больше MaxDelay,
+// ±10% случайно - чтобы письма, упавшие вместе, не повторялись тоже все разом
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.cfg.MaxDelay
+	if shift := attempts - 1; shift < 32 {
+		if d := q.cfg.BaseDelay << shift; d > 0 && d < delay {
+			delay = d
+		}
+	}
+	if jitter := int64(delay / 10); jitter > 0 {
+		delay += time.Duration(rand.Int64N(2*jitter+1) - jitter)
+	}
+	return delay
+}
+
+func (q *Queue) purgeSent(now time.Time) {
+	if q.cfg.KeepSent <= 0 {
+		return
+	}
+	deleted, err := q.repo.DeleteSentBefore(now.Add(-q.cfg.KeepSent))
+	if err != nil {
+		prettyprint.Error("Failed to purge sent emails: %v", err)
+		return
+	}
+	if deleted > 0 {
+		prettyprint.Info("Purged %d sent emails older than %s", deleted, q.cfg.KeepSent)
+	}
+}
+
+// idempotencyKey в базе хранится хеш ключа (в ключах бывают коды из писем);
+// без ключа письмо получает случайный
+func idempotencyKey(key string) (string, error) {
+	if key == "" {
+		random := make([]byte, 32)
+		if _, err := cryptorand.Read(random); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(random), nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func splitRecipients(to string) []string {
+	var recipients []string
+	for _, addr := range strings.Split(to, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			recipients = append(recipients, addr)
+		}
+	}
+	return recipients
+}
@@ -0,0 +1,183 @@
+// internal/password/policy.go
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"taskflow/internal/config"
+	"taskflow/internal/models"
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// Причины отказа (models.PasswordReason.Code) - по ним UI подбирает текст
+const (
+	ReasonTooShort      = "too_short"
+	ReasonTooLong       = "too_long"
+	ReasonCommon        = "common"
+	ReasonContainsEmail = "contains_email"
+	ReasonTooWeak       = "too_weak"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// Policy требования к новым паролям (регистрация, смена, сброс).
+// На вход с уже установленным паролем не влияет.
+type Policy struct {
+	minLength int
+	maxLength int
+	maxBytes  int // жёсткий предел алгоритма хеширования
+	minScore  int
+	ranked    map[string]int // частые пароли и слова: значение - место в списке (1 - самый частый)
+}
+
+// NewPolicy политика из конфигурации. maxBytes - сколько байт пароля
+// принимает алгоритм хеширования (0 - без ограничения).
+func NewPolicy(cfg config.PasswordConfig, maxBytes int) *Policy {
+	p := &Policy{
+		minLength: cfg.MinLength,
+		maxLength: cfg.MaxLength,
+		maxBytes:  maxBytes,
+		minScore:  cfg.MinScore,
+		ranked:    make(map[string]int),
+	}
+	p.addWords(strings.NewReader(commonPasswords))
+
+	if cfg.BannedFile != "" {
+		file, err := os.Open(cfg.BannedFile)
+		if err != nil {
+			prettyprint.Warn("Failed to load banned passwords from %s: %v", cfg.BannedFile, err)
+		} else {
+			p.addWords(file)
+			file.Close()
+		}
+	}
+	return p
+}
+
+// Requirements то, что стоит показать пользователю до ввода пароля
+func (p *Policy) Requirements() models.PasswordPolicyResponse {
+	return models.PasswordPolicyResponse{
+		MinLength: p.minLength,
+		MaxLength: p.effectiveMaxLength(),
+		MinScore:  p.minScore,
+	}
+}
+
+// effectiveMaxLength предел длины с учётом алгоритма хеширования
+// (байтов не меньше, чем символов, так что предел в байтах - гарантированный)
+func (p *Policy) effectiveMaxLength() int {
+	if p.maxBytes > 0 && (p.maxLength == 0 || p.maxBytes < p.maxLength) {
+		return p.maxBytes
+	}
+	return p.maxLength
+}
+
+// Check проверяет новый пароль. email и остальные personal (имя, фамилия) -
+// данные пользователя: их нельзя использовать в пароле и их подбирают первыми.
+// Пустой результат - пароль подходит.
+func (p *Policy) Check(password, email string, personal ...string) []models.PasswordReason {
+	var reasons []models.PasswordReason
+
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		reasons = append(reasons, models.PasswordReason{
+			Code:    ReasonTooShort,
+			Message: "Password is too short",
+			Limit:   p.minLength,
+		})
+	}
+	if (p.maxLength > 0 && length > p.maxLength) || (p.maxBytes > 0 && len(password) > p.maxBytes) {
+		reasons = append(reasons, models.PasswordReason{
+			Code:    ReasonTooLong,
+			Message: "Password is too long",
+			Limit:   p.effectiveMaxLength(),
+		})
+		// Оценивать стойкость такого пароля нет смысла, а длинный ввод дорог
+		return reasons
+	}
+
+	lower := strings.ToLower(password)
+	if p.isCommon(lower) {
+		reasons = append(reasons, models.PasswordReason{
+			Code:    ReasonCommon,
+			Message: "Password is too common",
+		})
+	}
+	if containsEmail(lower, email) {
+		reasons = append(reasons, models.PasswordReason{
+			Code:    ReasonContainsEmail,
+			Message: "Password must not contain your email",
+		})
+	}
+
+	inputs := append(personalWords(email), personal...)
+	if estimate := p.Estimate(password, inputs...); estimate.Score < p.minScore {
+		reasons = append(reasons, models.PasswordReason{
+			Code:    ReasonTooWeak,
+			Message: "Password is too easy to guess",
+			Limit:   p.minScore,
+			Score:   &estimate.Score,
+		})
+	}
+	return reasons
+}
+
+// isCommon пароль целиком из списка частых, в том числе в l33t-записи (p@ssw0rd)
+func (p *Policy) isCommon(lower string) bool {
+	if _, ok := p.ranked[lower]; ok {
+		return true
+	}
+	for _, variant := range unleet(lower) {
+		if _, ok := p.ranked[variant]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) addWords(source io.Reader) {
+	scanner := bufio.NewScanner(source)
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		if _, exists := p.ranked[word]; !exists {
+			p.ranked[word] = len(p.ranked) + 1
+		}
+	}
+}
+
+// containsEmail есть ли в пароле email целиком или его имя до @ (от 3 символов)
+func containsEmail(lower, email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false
+	}
+	if strings.Contains(lower, email) {
+		return true
+	}
+	local, _, _ := strings.Cut(email, "@")
+	return utf8.RuneCountInString(local) >= 3 && strings.Contains(lower, local)
+}
+
+// personalWords части email, которые люди любят вставлять в пароль: "ivan.petrov@..." -> ivan, petrov
+func personalWords(email string) []string {
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+	words := []string{local}
+	for _, part := range strings.FieldsFunc(local, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if part != local {
+			words = append(words, part)
+		}
+	}
+	return words
+}
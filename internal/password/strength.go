@@ -0,0 +1,364 @@
+// internal/password/strength.go
+package password
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Оценка стойкости по мотивам zxcvbn: пароль раскладывается на куски, которые
+// перебирают в первую очередь (частые пароли, данные пользователя, последовательности,
+// повторы, ряды клавиатуры, даты), остальное считается случайным перебором.
+// Берётся разбиение с наименьшим числом попыток.
+
+// Estimate результат оценки
+type Estimate struct {
+	Guesses float64 // log10 числа попыток, за которое пароль будет подобран
+	Score   int     // 0 - подбирается мгновенно ... 4 - очень стойкий
+}
+
+const (
+	bruteforceGuesses = 1.0 // log10(10): каждый «случайный» символ
+	minWordLength     = 3
+	maxWordLength     = 32
+	minYearSpace      = 20
+)
+
+// minSubmatchGuesses кусок внутри пароля не бывает совсем бесплатным (log10(50))
+var minSubmatchGuesses = math.Log10(50)
+
+// Клавиатурные ряды (латиница и ЙЦУКЕН) для поиска «qwerty», «asdf», «йцукен»
+var keyboardRows = []string{
+	"1234567890-=", "qwertyuiop[]", "asdfghjkl;'", "zxcvbnm,./",
+	"йцукенгшщзхъ", "фывапролджэ", "ячсмитьбю",
+}
+
+// l33t-замены: символ -> возможные буквы
+var leetTable = map[rune][]rune{
+	'4': {'a'}, '@': {'a'}, '8': {'b'}, '(': {'c'}, '{': {'c'}, '3': {'e'},
+	'6': {'g'}, '9': {'g'}, '1': {'i', 'l'}, '!': {'i'}, '|': {'i', 'l'},
+	'0': {'o'}, '$': {'s'}, '5': {'s'}, '7': {'t'}, '+': {'t'}, '2': {'z'},
+}
+
+type match struct {
+	start, end int     // руны [start, end]
+	guesses    float64 // log10
+}
+
+// Estimate оценивает стойкость пароля; inputs - слова пользователя (имя, части email)
+func (p *Policy) Estimate(password string, inputs ...string) Estimate {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return Estimate{}
+	}
+
+	userWords := make(map[string]int, len(inputs))
+	for _, input := range inputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if len([]rune(input)) >= minWordLength {
+			if _, exists := userWords[input]; !exists {
+				userWords[input] = len(userWords) + 1
+			}
+		}
+	}
+
+	var matches []match
+	matches = append(matches, p.dictionaryMatches(runes, userWords)...)
+	matches = append(matches, repeatMatches(runes)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, dateMatches(runes)...)
+
+	// best[k] - минимум попыток для первых k символов
+	n := len(runes)
+	best := make([]float64, n+1)
+	byEnd := make([][]match, n)
+	for _, m := range matches {
+		byEnd[m.end] = append(byEnd[m.end], m)
+	}
+	for k := 1; k <= n; k++ {
+		best[k] = best[k-1] + bruteforceGuesses
+		for _, m := range byEnd[k-1] {
+			guesses := m.guesses
+			if m.end-m.start+1 < n {
+				guesses = math.Max(guesses, minSubmatchGuesses)
+			}
+			if total := best[m.start] + guesses; total < best[k] {
+				best[k] = total
+			}
+		}
+	}
+
+	return Estimate{Guesses: best[n], Score: score(best[n])}
+}
+
+// score пороги zxcvbn: 10^3, 10^6, 10^8, 10^10 попыток
+func score(guesses float64) int {
+	switch {
+	case guesses < 3:
+		return 0
+	case guesses < 6:
+		return 1
+	case guesses < 8:
+		return 2
+	case guesses < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// dictionaryMatches частые пароли и слова пользователя, в том числе задом наперёд и в l33t
+func (p *Policy) dictionaryMatches(runes []rune, userWords map[string]int) []match {
+	var matches []match
+	lower := []rune(strings.ToLower(string(runes)))
+
+	rank := func(word string) (int, bool) {
+		if r, ok := userWords[word]; ok {
+			return r, true
+		}
+		r, ok := p.ranked[word]
+		return r, ok
+	}
+
+	for i := range lower {
+		for j := i + minWordLength - 1; j < len(lower) && j-i < maxWordLength; j++ {
+			original := runes[i : j+1]
+			word := string(lower[i : j+1])
+			variations := math.Log10(uppercaseVariations(original))
+
+			if r, ok := rank(word); ok {
+				matches = append(matches, match{i, j, math.Log10(float64(r)) + variations})
+			}
+			if r, ok := rank(reverse(word)); ok {
+				matches = append(matches, match{i, j, math.Log10(float64(r)) + variations + math.Log10(2)})
+			}
+			for _, variant := range unleet(word) {
+				if r, ok := rank(variant); ok {
+					matches = append(matches, match{i, j, math.Log10(float64(r)) + variations + math.Log10(2)})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// repeatMatches один символ подряд: "aaaa", "1111"
+func repeatMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i < len(runes); {
+		j := i
+		for j+1 < len(runes) && runes[j+1] == runes[i] {
+			j++
+		}
+		if j-i+1 >= 3 {
+			matches = append(matches, match{i, j, math.Log10(float64(cardinality(runes[i]) * (j - i + 1)))})
+		}
+		i = j + 1
+	}
+	return matches
+}
+
+// sequenceMatches "abcd", "4321", "зиюя" - шаг ±1 по кодам символов
+func sequenceMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i+2 < len(runes); {
+		delta := runes[i+1] - runes[i]
+		if delta != 1 && delta != -1 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+			j++
+		}
+		if j-i+1 >= 3 {
+			base := float64(cardinality(runes[i]))
+			switch unicode.ToLower(runes[i]) {
+			case 'a', 'z', '0', '1', '9', 'а', 'я':
+				base = 4 // очевидное начало
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, match{i, j, math.Log10(base * float64(j-i+1))})
+		}
+		i = j
+	}
+	return matches
+}
+
+// keyboardMatches отрезки клавиатурных рядов от 4 символов, в любую сторону
+func keyboardMatches(runes []rune) []match {
+	var matches []match
+	lower := strings.ToLower(string(runes))
+	lowerRunes := []rune(lower)
+	for i := range lowerRunes {
+		for j := i + 3; j < len(lowerRunes); j++ {
+			chunk := string(lowerRunes[i : j+1])
+			for _, row := range keyboardRows {
+				if strings.Contains(row, chunk) || strings.Contains(row, reverse(chunk)) {
+					matches = append(matches, match{i, j, math.Log10(float64(40 * (j - i + 1)))})
+					break
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// dateMatches годы (1987, 2024) и даты из цифр подряд (01021990, 19900102, 010290)
+func dateMatches(runes []rune) []match {
+	var matches []match
+	now := time.Now().Year()
+
+	yearSpace := func(year int) float64 {
+		return math.Max(math.Abs(float64(year-now)), minYearSpace)
+	}
+
+	for i := range runes {
+		for _, length := range []int{4, 6, 8} {
+			j := i + length - 1
+			if j >= len(runes) || !allDigits(runes[i:j+1]) {
+				continue
+			}
+			digits := string(runes[i : j+1])
+			if length == 4 {
+				if year, _ := strconv.Atoi(digits); year >= 1900 && year <= 2099 {
+					matches = append(matches, match{i, j, math.Log10(yearSpace(year))})
+				}
+				continue
+			}
+			if year, ok := parseDate(digits); ok {
+				matches = append(matches, match{i, j, math.Log10(365 * yearSpace(year))})
+			}
+		}
+	}
+	return matches
+}
+
+// parseDate DDMMYYYY, MMDDYYYY, YYYYMMDD или DDMMYY; возвращает год
+func parseDate(digits string) (int, bool) {
+	num := func(s string) int { n, _ := strconv.Atoi(s); return n }
+	validDay := func(d, m int) bool { return d >= 1 && d <= 31 && m >= 1 && m <= 12 }
+
+	if len(digits) == 6 {
+		d, m, y := num(digits[0:2]), num(digits[2:4]), num(digits[4:6])
+		if validDay(d, m) || validDay(m, d) {
+			if y < 50 {
+				return 2000 + y, true
+			}
+			return 1900 + y, true
+		}
+		return 0, false
+	}
+
+	if y := num(digits[4:8]); y >= 1900 && y <= 2099 {
+		a, b := num(digits[0:2]), num(digits[2:4])
+		if validDay(a, b) || validDay(b, a) {
+			return y, true
+		}
+	}
+	if y := num(digits[0:4]); y >= 1900 && y <= 2099 && validDay(num(digits[6:8]), num(digits[4:6])) {
+		return y, true
+	}
+	return 0, false
+}
+
+// uppercaseVariations сколько вариантов регистра перебирать для слова:
+// всё строчными - 1, Первая/последняя/ВСЕ заглавные - 2, иначе сочетания позиций
+func uppercaseVariations(word []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(word[0]) || unicode.IsUpper(word[len(word)-1]))) {
+		return 2
+	}
+
+	variations := 0.0
+	for k := 1; k <= upper && k <= lower; k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return math.Max(variations, 2)
+}
+
+// unleet варианты слова с раскрытыми l33t-заменами (пустой, если замен нет)
+func unleet(word string) []string {
+	variants := []string{""}
+	changed := false
+	for _, r := range word {
+		subs, ok := leetTable[r]
+		if !ok {
+			for i := range variants {
+				variants[i] += string(r)
+			}
+			continue
+		}
+		changed = true
+		next := make([]string, 0, len(variants)*len(subs))
+		for _, v := range variants {
+			for _, s := range subs {
+				next = append(next, v+string(s))
+			}
+		}
+		// Неоднозначных замен в одном слове немного; не даём вариантам разрастись
+		if len(next) > 16 {
+			next = next[:16]
+		}
+		variants = next
+	}
+	if !changed {
+		return nil
+	}
+	return variants
+}
+
+func cardinality(r rune) int {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case r < unicode.MaxASCII && unicode.IsLetter(r):
+		return 26
+	case unicode.IsLetter(r):
+		return 33 // кириллица и прочие алфавиты
+	default:
+		return 33 // спецсимволы
+	}
+}
+
+func allDigits(runes []rune) bool {
+	for _, r := range runes {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
@@ -0,0 +1,244 @@
+// internal/realtime/conn.go
+package realtime
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+	"golang.org/x/time/rate"
+
+	"taskflow/internal/events"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+const (
+	sendBuffer      = 64               // исходящих сообщений в очереди соединения
+	maxMessageBytes = 4096             // максимальный размер входящего сообщения
+	writeTimeout    = 10 * time.Second // на запись одного сообщения
+	readTimeout     = 70 * time.Second // клиент должен что-то прислать (хотя бы pong)
+	pingInterval    = 30 * time.Second
+)
+
+// incoming сообщение от клиента
+type incoming struct {
+	Type  string `json:"type"` // subscribe | unsubscribe | typing | pong
+	Topic string `json:"topic"`
+}
+
+// outgoing сообщение клиенту
+type outgoing struct {
+	Type    string      `json:"type"`
+	Topic   string      `json:"topic,omitempty"`
+	Event   string      `json:"event,omitempty"`
+	Member  *Member     `json:"member,omitempty"`
+	Members []Member    `json:"members,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Conn одно WebSocket-соединение
+type Conn struct {
+	id      string
+	userID  uint
+	name    string
+	ws      *websocket.Conn
+	hub     *Hub
+	send    chan []byte
+	done    chan struct{}
+	once    sync.Once
+	limiter *rate.Limiter
+
+	topics map[string]string // внутренний ключ → топик как его назвал клиент (под hub.mu)
+}
+
+func (c *Conn) member() Member {
+	return Member{ConnID: c.id, UserID: c.userID, Name: c.name}
+}
+
+// Serve обслуживает соединение до его закрытия
+func (h *Hub) Serve(ws *websocket.Conn, userID uint, name string) {
+	ws.MaxPayloadBytes = maxMessageBytes
+	// Дедлайны http.Server остаются на hijacked-соединении - сбрасываем
+	_ = ws.SetDeadline(time.Time{})
+
+	c := &Conn{
+		userID:  userID,
+		name:    name,
+		ws:      ws,
+		hub:     h,
+		send:    make(chan []byte, sendBuffer),
+		done:    make(chan struct{}),
+		limiter: rate.NewLimiter(rate.Limit(20), 40),
+		topics:  make(map[string]string),
+	}
+
+	if !h.register(c) {
+		ws.Close()
+		return
+	}
+	defer h.unregister(c)
+
+	sub, _, _ := h.events.Subscribe(userID, 0)
+	defer h.events.Unsubscribe(sub)
+
+	go c.writeLoop()
+	go c.forwardEvents(sub)
+
+	c.enqueue(outgoing{Type: "hello", Member: &Member{ConnID: c.id, UserID: userID, Name: name}})
+	c.readLoop()
+	c.close("")
+}
+
+func (c *Conn) readLoop() {
+	for {
+		_ = c.ws.SetReadDeadline(time.Now().Add(readTimeout))
+
+		var msg incoming
+		if err := websocket.JSON.Receive(c.ws, &msg); err != nil {
+			if err == websocket.ErrFrameTooLarge {
+				c.close("message too large")
+			}
+			return
+		}
+
+		if !c.limiter.Allow() {
+			c.close("rate limit exceeded")
+			return
+		}
+
+		c.handle(msg)
+	}
+}
+
+func (c *Conn) handle(msg incoming) {
+	switch msg.Type {
+	case "pong":
+		// просто продлевает read deadline
+	case "subscribe":
+		key, err := c.hub.resolveTopic(c.userID, msg.Topic)
+		if err != nil {
+			c.enqueue(outgoing{Type: "error", Topic: msg.Topic, Error: err.Error()})
+			return
+		}
+		members, err := c.hub.join(c, key, msg.Topic)
+		if err != nil {
+			c.enqueue(outgoing{Type: "error", Topic: msg.Topic, Error: err.Error()})
+			return
+		}
+		c.enqueue(outgoing{Type: "subscribed", Topic: msg.Topic, Members: members})
+	case "unsubscribe":
+		if key, err := c.hub.resolveTopic(c.userID, msg.Topic); err == nil {
+			c.hub.leave(c, key)
+		}
+		c.enqueue(outgoing{Type: "unsubscribed", Topic: msg.Topic})
+	case "typing":
+		if key, err := c.hub.resolveTopic(c.userID, msg.Topic); err == nil {
+			c.hub.typing(c, key)
+		}
+	default:
+		c.enqueue(outgoing{Type: "error", Error: "unknown message type"})
+	}
+}
+
+// forwardEvents пересылает изменения задач из общего хаба событий
+func (c *Conn) forwardEvents(sub *events.Subscription) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// хаб событий отключил нас (переполнение или остановка)
+				c.close("event stream closed")
+				return
+			}
+			if taskID, ok := taskIDFromEvent(e); ok {
+				c.hub.deliverTaskEvent(c, e, taskID)
+			}
+		}
+	}
+}
+
+func (c *Conn) writeLoop() {
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case payload := <-c.send:
+			if err := c.write(payload); err != nil {
+				c.close("")
+				return
+			}
+		case <-ping.C:
+			if err := c.write([]byte(`{"type":"ping"}`)); err != nil {
+				c.close("")
+				return
+			}
+		}
+	}
+}
+
+func (c *Conn) write(payload []byte) error {
+	_ = c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return websocket.Message.Send(c.ws, string(payload))
+}
+
+func (c *Conn) enqueue(msg outgoing) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		prettyprint.Error("Realtime: failed to encode message: %v", err)
+		return
+	}
+	c.enqueueRaw(payload)
+}
+
+// enqueueRaw кладёт сообщение в очередь. Если клиент не успевает читать
+// и очередь заполнена - соединение закрывается (backpressure).
+func (c *Conn) enqueueRaw(payload []byte) {
+	select {
+	case <-c.done:
+	case c.send <- payload:
+	default:
+		prettyprint.Warn("Realtime: connection %s is too slow, closing", c.id)
+		go c.close("too slow")
+	}
+}
+
+// close закрывает соединение один раз; reason уходит клиенту, если успеем
+func (c *Conn) close(reason string) {
+	c.once.Do(func() {
+		close(c.done)
+		if reason != "" {
+			payload, _ := json.Marshal(outgoing{Type: "closing", Error: reason})
+			_ = c.write(payload)
+		}
+		c.ws.Close()
+	})
+}
+
+func taskIDFromEvent(e events.Event) (uint, bool) {
+	switch e.Type {
+	case events.TaskCreated, events.TaskUpdated, events.TaskDeleted:
+	default:
+		return 0, false
+	}
+
+	// Data может быть любым JSON-совместимым значением - достаём id универсально
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return 0, false
+	}
+	var payload struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.ID == 0 {
+		return 0, false
+	}
+	return payload.ID, true
+}
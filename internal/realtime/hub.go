@@ -0,0 +1,208 @@
+// internal/realtime/hub.go
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"taskflow/internal/events"
+	"taskflow/internal/repository"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// Топики, на которые может подписаться клиент:
+//
+//	"tasks"    - весь список задач пользователя
+//	"task:<id>" - одна задача (проверяем, что она принадлежит пользователю)
+//
+// Внутри хаба топики хранятся с владельцем ("tasks:<userID>"), чтобы
+// подписки разных пользователей никогда не пересекались.
+const maxTopicsPerConn = 50
+
+// Member участник топика (для presence)
+type Member struct {
+	ConnID string `json:"connId"`
+	UserID uint   `json:"userId"`
+	Name   string `json:"name"`
+}
+
+// Hub держит все WebSocket-соединения и их подписки
+type Hub struct {
+	mu       sync.Mutex
+	topics   map[string]map[*Conn]struct{}
+	conns    map[*Conn]struct{}
+	nextID   uint64
+	closed   bool
+	events   *events.Hub
+	taskRepo *repository.TaskRepository
+}
+
+func NewHub(eventsHub *events.Hub, taskRepo *repository.TaskRepository) *Hub {
+	return &Hub{
+		topics:   make(map[string]map[*Conn]struct{}),
+		conns:    make(map[*Conn]struct{}),
+		events:   eventsHub,
+		taskRepo: taskRepo,
+	}
+}
+
+// register добавляет соединение; false - хаб уже остановлен
+func (h *Hub) register(c *Conn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+	h.nextID++
+	c.id = strconv.FormatUint(h.nextID, 10)
+	h.conns[c] = struct{}{}
+	return true
+}
+
+// unregister убирает соединение из всех топиков и сообщает остальным, что оно ушло
+func (h *Hub) unregister(c *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for topic := range c.topics {
+		h.leaveLocked(c, topic)
+	}
+	delete(h.conns, c)
+}
+
+// resolveTopic проверяет доступ и возвращает внутренний ключ топика
+func (h *Hub) resolveTopic(userID uint, topic string) (string, error) {
+	if topic == "tasks" {
+		return fmt.Sprintf("tasks:%d", userID), nil
+	}
+
+	if rest, ok := strings.CutPrefix(topic, "task:"); ok {
+		taskID, err := strconv.ParseUint(rest, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid topic")
+		}
+		if _, err := h.taskRepo.GetUserTask(userID, uint(taskID)); err != nil {
+			return "", fmt.Errorf("task not found")
+		}
+		return fmt.Sprintf("task:%d", taskID), nil
+	}
+
+	return "", fmt.Errorf("unknown topic")
+}
+
+// join подписывает соединение на топик и возвращает текущих участников
+func (h *Hub) join(c *Conn, key, topic string) ([]Member, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if c.topics[key] != "" {
+		return h.membersLocked(key), nil
+	}
+	if len(c.topics) >= maxTopicsPerConn {
+		return nil, fmt.Errorf("too many subscriptions")
+	}
+
+	if h.topics[key] == nil {
+		h.topics[key] = make(map[*Conn]struct{})
+	}
+	h.topics[key][c] = struct{}{}
+	c.topics[key] = topic
+
+	h.broadcastLocked(key, c, presenceMessage(topic, "joined", c.member()))
+	return h.membersLocked(key), nil
+}
+
+// leave отписывает соединение от топика
+func (h *Hub) leave(c *Conn, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.leaveLocked(c, key)
+}
+
+func (h *Hub) leaveLocked(c *Conn, key string) {
+	topic, ok := c.topics[key]
+	if !ok {
+		return
+	}
+	delete(c.topics, key)
+	delete(h.topics[key], c)
+	if len(h.topics[key]) == 0 {
+		delete(h.topics, key)
+	}
+
+	h.broadcastLocked(key, c, presenceMessage(topic, "left", c.member()))
+}
+
+// typing рассылает "печатает..." остальным участникам топика
+func (h *Hub) typing(c *Conn, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	topic, ok := c.topics[key]
+	if !ok {
+		return
+	}
+	h.broadcastLocked(key, c, presenceMessage(topic, "typing", c.member()))
+}
+
+// deliverTaskEvent отправляет событие о задаче соединению, если оно
+// подписано на список задач или на эту задачу
+func (h *Hub) deliverTaskEvent(c *Conn, e events.Event, taskID uint) {
+	h.mu.Lock()
+	listTopic, onList := c.topics[fmt.Sprintf("tasks:%d", c.userID)]
+	taskTopic, onTask := c.topics[fmt.Sprintf("task:%d", taskID)]
+	h.mu.Unlock()
+
+	if onList {
+		c.enqueue(outgoing{Type: e.Type, Topic: listTopic, Data: e.Data})
+	}
+	if onTask {
+		c.enqueue(outgoing{Type: e.Type, Topic: taskTopic, Data: e.Data})
+	}
+}
+
+func (h *Hub) membersLocked(key string) []Member {
+	members := make([]Member, 0, len(h.topics[key]))
+	for conn := range h.topics[key] {
+		members = append(members, conn.member())
+	}
+	return members
+}
+
+func (h *Hub) broadcastLocked(key string, except *Conn, msg outgoing) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		prettyprint.Error("Realtime: failed to encode message: %v", err)
+		return
+	}
+	for conn := range h.topics[key] {
+		if conn != except {
+			conn.enqueueRaw(payload)
+		}
+	}
+}
+
+// Close закрывает все соединения. Вызывается при остановке сервера:
+// hijacked-соединения http.Server.Shutdown сам не закрывает.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*Conn, 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range conns {
+		c.close("server shutting down")
+	}
+}
+
+func presenceMessage(topic, event string, member Member) outgoing {
+	return outgoing{Type: "presence", Topic: topic, Event: event, Member: &member}
+}
@@ -0,0 +1,96 @@
+// internal/reminder/scheduler.go
+package reminder
+
+import (
+	"context"
+	"time"
+
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/repository"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+// batchSize сколько напоминаний обрабатываем за один тик
+const batchSize = 100
+
+// Scheduler доставляет напоминания о задачах. Всё состояние лежит в БД,
+// поэтому после перезапуска пропущенные напоминания уйдут на первом же тике.
+type Scheduler struct {
+	repo     *repository.ReminderRepository
+	taskRepo *repository.TaskRepository
+	notifier *notify.Service
+	interval time.Duration
+}
+
+func NewScheduler(repo *repository.ReminderRepository, taskRepo *repository.TaskRepository, notifier *notify.Service) *Scheduler {
+	return &Scheduler{
+		repo:     repo,
+		taskRepo: taskRepo,
+		notifier: notifier,
+		interval: 15 * time.Second,
+	}
+}
+
+// Run блокируется до отмены ctx
+func (s *Scheduler) Run(ctx context.Context) {
+	prettyprint.Info("Reminder scheduler started")
+
+	// Сразу догоняем то, что пропустили, пока сервер лежал
+	s.tick(time.Now())
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			prettyprint.Info("Reminder scheduler stopped")
+			return
+		case now := <-ticker.C:
+			s.tick(now)
+		}
+	}
+}
+
+func (s *Scheduler) tick(now time.Time) {
+	reminders, err := s.repo.ListDue(now, batchSize)
+	if err != nil {
+		prettyprint.Error("Reminder scheduler: failed to list reminders: %v", err)
+		return
+	}
+
+	for _, r := range reminders {
+		claimed, err := s.repo.MarkSent(r.ID, now)
+		if err != nil {
+			prettyprint.Error("Reminder scheduler: failed to claim reminder %d: %v", r.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		task, err := s.taskRepo.GetUserTask(r.UserID, r.TaskID)
+		if err != nil {
+			prettyprint.Warn("Reminder %d: task %d not found, skipping", r.ID, r.TaskID)
+			continue
+		}
+		if task.Completed {
+			prettyprint.Debug("Reminder %d: task %d already completed, skipping", r.ID, r.TaskID)
+			continue
+		}
+
+		s.notifier.Notify(r.UserID, reminderMessage(task))
+		prettyprint.Debug("Reminder %d delivered", r.ID)
+	}
+}
+
+func reminderMessage(task *models.Task) notify.Message {
+	return notify.Message{
+		Type: models.NotificationReminder,
+		Key:  notify.MsgReminder,
+		Data: map[string]interface{}{"Task": task.Title, "Due": task.DueAt},
+		Link: "/tasks",
+	}
+}
@@ -0,0 +1,59 @@
+// internal/repository/api_token_repo.go
+package repository
+
+import (
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+)
+
+type APITokenRepository struct{}
+
+func NewAPITokenRepository() *APITokenRepository {
+	return &APITokenRepository{}
+}
+
+// Создание токена
+func (r *APITokenRepository) Create(token *models.APIToken) error {
+	return database.DB.Create(token).Error
+}
+
+// GetByHash токен по хешу
+func (r *APITokenRepository) GetByHash(hash string) (*models.APIToken, error) {
+	var token models.APIToken
+	err := database.DB.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListByUser неотозванные токены пользователя (новые сверху)
+func (r *APITokenRepository) ListByUser(userID uint) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	err := database.DB.
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at desc").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// Revoke отзывает токен пользователя (false - не найден)
+func (r *APITokenRepository) Revoke(userID, id uint) (bool, error) {
+	result := database.DB.Model(&models.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeAllForUser отзывает все токены пользователя
+func (r *APITokenRepository) RevokeAllForUser(userID uint) error {
+	return database.DB.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// TouchLastUsed время последнего использования
+func (r *APITokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	return database.DB.Model(&models.APIToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -0,0 +1,99 @@
+// internal/repository/audit_repo.go
+package repository
+
+import (
+	"strings"
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct{}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+type AuditFilter struct {
+	Action  string // точное действие ("auth.login") или префикс группы ("auth.")
+	Outcome string
+	UserID  *uint
+	ActorID *uint
+	Email   string
+	IP      string
+	From    time.Time // включительно; нулевое значение - без ограничения
+	To      time.Time // не включительно
+}
+
+// Create дописывает событие в журнал
+func (r *AuditRepository) Create(event *models.AuditEvent) error {
+	return database.DB.Create(event).Error
+}
+
+// Search события по фильтру (новые сверху) и общее количество найденных
+func (r *AuditRepository) Search(filter AuditFilter, limit, offset int) ([]models.AuditEvent, int64, error) {
+	query := filter.apply(database.DB.Model(&models.AuditEvent{}))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []models.AuditEvent
+	err := query.Order("id desc").Limit(limit).Offset(offset).Find(&events).Error
+	return events, total, err
+}
+
+// Each обходит все события по фильтру в порядке записи, пачками по batch штук -
+// для выгрузки журнала целиком без загрузки в память
+func (r *AuditRepository) Each(filter AuditFilter, batch int, fn func(*models.AuditEvent) error) error {
+	var events []models.AuditEvent
+	return filter.apply(database.DB.Model(&models.AuditEvent{})).
+		FindInBatches(&events, batch, func(tx *gorm.DB, _ int) error {
+			for i := range events {
+				if err := fn(&events[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// DeleteBefore удаляет события старше before (срок хранения журнала)
+func (r *AuditRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := database.DB.Where("created_at < ?", before).Delete(&models.AuditEvent{})
+	return result.RowsAffected, result.Error
+}
+
+func (f AuditFilter) apply(query *gorm.DB) *gorm.DB {
+	switch {
+	case strings.HasSuffix(f.Action, "."):
+		query = query.Where("action LIKE ?", f.Action+"%")
+	case f.Action != "":
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.Outcome != "" {
+		query = query.Where("outcome = ?", f.Outcome)
+	}
+	if f.UserID != nil {
+		query = query.Where("user_id = ?", *f.UserID)
+	}
+	if f.ActorID != nil {
+		query = query.Where("actor_id = ?", *f.ActorID)
+	}
+	if f.Email != "" {
+		query = query.Where("LOWER(email) = ?", strings.ToLower(f.Email))
+	}
+	if f.IP != "" {
+		query = query.Where("ip = ?", f.IP)
+	}
+	if !f.From.IsZero() {
+		query = query.Where("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		query = query.Where("created_at < ?", f.To)
+	}
+	return query
+}
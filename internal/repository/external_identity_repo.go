@@ -0,0 +1,49 @@
+// internal/repository/external_identity_repo.go
+package repository
+
+import (
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+)
+
+type ExternalIdentityRepository struct{}
+
+func NewExternalIdentityRepository() *ExternalIdentityRepository {
+	return &ExternalIdentityRepository{}
+}
+
+// Create привязка внешнего аккаунта
+func (r *ExternalIdentityRepository) Create(identity *models.ExternalIdentity) error {
+	return database.DB.Create(identity).Error
+}
+
+// GetBySubject привязка по провайдеру и его ID пользователя
+func (r *ExternalIdentityRepository) GetBySubject(provider, subject string) (*models.ExternalIdentity, error) {
+	var identity models.ExternalIdentity
+	err := database.DB.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListByUser привязанные аккаунты пользователя
+func (r *ExternalIdentityRepository) ListByUser(userID uint) ([]models.ExternalIdentity, error) {
+	var identities []models.ExternalIdentity
+	err := database.DB.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+// TouchLogin время входа и актуальный email у провайдера
+func (r *ExternalIdentityRepository) TouchLogin(id uint, email string, at time.Time) error {
+	return database.DB.Model(&models.ExternalIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"email": email, "last_login_at": at}).Error
+}
+
+// Delete отвязывает аккаунт (false - не найден у этого пользователя)
+func (r *ExternalIdentityRepository) Delete(userID, id uint) (bool, error) {
+	result := database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.ExternalIdentity{})
+	return result.RowsAffected > 0, result.Error
+}
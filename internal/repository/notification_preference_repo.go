@@ -0,0 +1,52 @@
+// internal/repository/notification_preference_repo.go
+package repository
+
+import (
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepository struct{}
+
+func NewNotificationPreferenceRepository() *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{}
+}
+
+// GetByUser все явно заданные настройки пользователя (тип события → режим)
+func (r *NotificationPreferenceRepository) GetByUser(userID uint) (map[models.NotificationType]models.DeliveryMode, error) {
+	var prefs []models.NotificationPreference
+	if err := database.DB.Where("user_id = ?", userID).Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[models.NotificationType]models.DeliveryMode, len(prefs))
+	for _, p := range prefs {
+		result[p.EventType] = p.Delivery
+	}
+	return result, nil
+}
+
+// Get настройка для одного типа события (nil - если не задана)
+func (r *NotificationPreferenceRepository) Get(userID uint, eventType models.NotificationType) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := database.DB.Where("user_id = ? AND event_type = ?", userID, eventType).Limit(1).Find(&pref).Error
+	if err != nil || pref.ID == 0 {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// Upsert создаёт или обновляет настройку
+func (r *NotificationPreferenceRepository) Upsert(userID uint, eventType models.NotificationType, delivery models.DeliveryMode) error {
+	pref := models.NotificationPreference{
+		UserID:    userID,
+		EventType: eventType,
+		Delivery:  delivery,
+	}
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"delivery", "updated_at"}),
+	}).Create(&pref).Error
+}
@@ -0,0 +1,83 @@
+// internal/repository/notification_repo.go
+package repository
+
+import (
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+)
+
+type NotificationRepository struct{}
+
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{}
+}
+
+// Создание уведомления
+func (r *NotificationRepository) Create(n *models.Notification) error {
+	return database.DB.Create(n).Error
+}
+
+// ListByUser возвращает уведомления пользователя (новые сверху) и общее количество
+func (r *NotificationRepository) ListByUser(userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	var (
+		notifications []models.Notification
+		total         int64
+	)
+
+	query := database.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at desc").Limit(limit).Offset(offset).Find(&notifications).Error
+	return notifications, total, err
+}
+
+// CountUnread количество непрочитанных уведомлений
+func (r *NotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead отмечает одно уведомление прочитанным (false - если не найдено)
+func (r *NotificationRepository) MarkRead(userID, id uint) (bool, error) {
+	var n models.Notification
+	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&n).Error; err != nil {
+		return false, err
+	}
+	if n.ReadAt != nil {
+		return true, nil
+	}
+
+	err := database.DB.Model(&n).Update("read_at", time.Now()).Error
+	return err == nil, err
+}
+
+// MarkAllRead отмечает прочитанными все уведомления пользователя
+func (r *NotificationRepository) MarkAllRead(userID uint) (int64, error) {
+	result := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// ListSince уведомления пользователя указанных типов, созданные после since
+func (r *NotificationRepository) ListSince(userID uint, since time.Time, types []models.NotificationType) ([]models.Notification, error) {
+	var notifications []models.Notification
+	if len(types) == 0 {
+		return notifications, nil
+	}
+	err := database.DB.
+		Where("user_id = ? AND created_at > ? AND type IN ?", userID, since, types).
+		Order("created_at asc").
+		Find(&notifications).Error
+	return notifications, err
+}
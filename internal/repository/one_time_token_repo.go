@@ -0,0 +1,69 @@
+// internal/repository/one_time_token_repo.go
+package repository
+
+import (
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OneTimeTokenRepository struct{}
+
+func NewOneTimeTokenRepository() *OneTimeTokenRepository {
+	return &OneTimeTokenRepository{}
+}
+
+// Replace гасит прежние токены пользователя того же назначения и сохраняет новый
+func (r *OneTimeTokenRepository) Replace(token *models.OneTimeToken) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.OneTimeToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// GetByTokenHash токен по хешу секрета из ссылки
+func (r *OneTimeTokenRepository) GetByTokenHash(purpose models.TokenPurpose, hash string) (*models.OneTimeToken, error) {
+	var token models.OneTimeToken
+	err := database.DB.Where("purpose = ? AND token_hash = ?", purpose, hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetLatest последний выданный пользователю токен этого назначения
+func (r *OneTimeTokenRepository) GetLatest(userID uint, purpose models.TokenPurpose) (*models.OneTimeToken, error) {
+	var token models.OneTimeToken
+	err := database.DB.Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("id desc").
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// AddAttempt засчитывает неверный код; после maxAttempts токен сгорает
+func (r *OneTimeTokenRepository) AddAttempt(id uint, maxAttempts int) error {
+	return database.DB.Model(&models.OneTimeToken{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts": gorm.Expr("attempts + 1"),
+			"used_at":  gorm.Expr("CASE WHEN attempts + 1 >= ? THEN ? ELSE used_at END", maxAttempts, time.Now()),
+		}).Error
+}
+
+// MarkUsed атомарно помечает токен использованным (false - уже использован)
+func (r *OneTimeTokenRepository) MarkUsed(id uint) (bool, error) {
+	result := database.DB.Model(&models.OneTimeToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
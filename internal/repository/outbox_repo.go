@@ -0,0 +1,176 @@
+// internal/repository/outbox_repo.go
+package repository
+
+import (
+	"strings"
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository struct{}
+
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{}
+}
+
+// Enqueue ставит письмо в очередь. false - письмо с таким ключом уже есть, новое не добавлено.
+func (r *OutboxRepository) Enqueue(email *models.OutboxEmail) (bool, error) {
+	result := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "idempotency_key"}},
+		DoNothing: true,
+	}).Create(email)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimDue берёт до limit писем, которым пора уходить, и закрепляет их за вызывающим на lease.
+// Письма в состоянии sending с истёкшим закреплением - от упавшего обработчика - берутся снова.
+func (r *OutboxRepository) ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.OutboxEmail, error) {
+	due := database.DB.
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+			models.OutboxPending, now, models.OutboxSending, now)
+
+	var candidates []models.OutboxEmail
+	err := database.DB.Where(due).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lockedUntil := now.Add(lease)
+	claimed := candidates[:0]
+	for _, email := range candidates {
+		// Условие повторяет выборку: если письмо успел взять кто-то другой, строка не обновится
+		result := database.DB.Model(&models.OutboxEmail{}).
+			Where("id = ?", email.ID).
+			Where(due).
+			Updates(map[string]interface{}{
+				"status":       models.OutboxSending,
+				"locked_until": lockedUntil,
+			})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected > 0 {
+			email.Status = models.OutboxSending
+			email.LockedUntil = &lockedUntil
+			claimed = append(claimed, email)
+		}
+	}
+	return claimed, nil
+}
+
+// Release возвращает взятое, но не отправленное письмо в очередь (остановка сервера)
+func (r *OutboxRepository) Release(id uint) error {
+	return database.DB.Model(&models.OutboxEmail{}).
+		Where("id = ? AND status = ?", id, models.OutboxSending).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxPending,
+			"locked_until": nil,
+		}).Error
+}
+
+// MarkSent письмо доставлено. Текст стирается: коды и ссылки из писем не должны лежать в базе.
+func (r *OutboxRepository) MarkSent(id uint, attempts int) error {
+	return database.DB.Model(&models.OutboxEmail{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxSent,
+			"attempts":     attempts,
+			"sent_at":      time.Now(),
+			"locked_until": nil,
+			"last_error":   "",
+			"html":         "",
+			"text":         "",
+		}).Error
+}
+
+// MarkFailed неудачная попытка: письмо ждёт следующей в nextAttempt или, если dead, уходит в мёртвые
+func (r *OutboxRepository) MarkFailed(id uint, attempts int, lastError string, nextAttempt time.Time, dead bool) error {
+	status := models.OutboxPending
+	if dead {
+		status = models.OutboxDead
+	}
+	if len(lastError) > 1000 {
+		lastError = lastError[:1000]
+	}
+	return database.DB.Model(&models.OutboxEmail{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"attempts":        attempts,
+			"last_error":      lastError,
+			"next_attempt_at": nextAttempt,
+			"locked_until":    nil,
+		}).Error
+}
+
+// Retry возвращает мёртвое письмо в очередь с новым счётчиком попыток (false - письма нет или оно не мёртвое)
+func (r *OutboxRepository) Retry(id uint) (bool, error) {
+	result := database.DB.Model(&models.OutboxEmail{}).
+		Where("id = ? AND status = ?", id, models.OutboxDead).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Search письма очереди (новые сверху); status и to - необязательные фильтры
+func (r *OutboxRepository) Search(status, to string, limit, offset int) ([]models.OutboxEmail, int64, error) {
+	query := database.DB.Model(&models.OutboxEmail{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if to != "" {
+		query = query.Where("LOWER(\"to\") LIKE ?", "%"+strings.ToLower(to)+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var emails []models.OutboxEmail
+	err := query.Order("id desc").Limit(limit).Offset(offset).Find(&emails).Error
+	return emails, total, err
+}
+
+// CountByStatus сколько писем в каждом состоянии
+func (r *OutboxRepository) CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := database.DB.Model(&models.OutboxEmail{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{
+		models.OutboxPending: 0,
+		models.OutboxSending: 0,
+		models.OutboxSent:    0,
+		models.OutboxDead:    0,
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// DeleteSentBefore удаляет отправленные письма старше before (вместе с ними истекают ключи идемпотентности)
+func (r *OutboxRepository) DeleteSentBefore(before time.Time) (int64, error) {
+	result := database.DB.
+		Where("status = ? AND sent_at < ?", models.OutboxSent, before).
+		Delete(&models.OutboxEmail{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,59 @@
+// internal/repository/refresh_token_repo.go
+package repository
+
+import (
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+)
+
+type RefreshTokenRepository struct{}
+
+func NewRefreshTokenRepository() *RefreshTokenRepository {
+	return &RefreshTokenRepository{}
+}
+
+// Создание refresh-токена
+func (r *RefreshTokenRepository) Create(token *models.RefreshToken) error {
+	return database.DB.Create(token).Error
+}
+
+// GetByHash поиск по хешу токена
+func (r *RefreshTokenRepository) GetByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := database.DB.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed атомарно помечает токен обменянным.
+// false - его уже использовали (гонка или повторное предъявление).
+func (r *RefreshTokenRepository) MarkUsed(id uint) (bool, error) {
+	result := database.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeFamily отзывает все токены семейства
+func (r *RefreshTokenRepository) RevokeFamily(familyID string) error {
+	return database.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser отзывает все refresh-токены пользователя
+func (r *RefreshTokenRepository) RevokeAllForUser(userID uint) error {
+	return database.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUserExcept отзывает refresh-токены пользователя, кроме семейства exceptFamilyID
+func (r *RefreshTokenRepository) RevokeAllForUserExcept(userID uint, exceptFamilyID string) error {
+	return database.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, exceptFamilyID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,97 @@
+// internal/repository/reminder_repo.go
+package repository
+
+import (
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+)
+
+type ReminderRepository struct{}
+
+func NewReminderRepository() *ReminderRepository {
+	return &ReminderRepository{}
+}
+
+// Создание напоминания
+func (r *ReminderRepository) Create(reminder *models.Reminder) error {
+	return database.DB.Create(reminder).Error
+}
+
+// GetByTask все напоминания задачи
+func (r *ReminderRepository) GetByTask(userID, taskID uint) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	err := database.DB.Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("remind_at asc").
+		Find(&reminders).Error
+	return reminders, err
+}
+
+// GetUserReminder одно напоминание с проверкой владельца
+func (r *ReminderRepository) GetUserReminder(userID, id uint) (*models.Reminder, error) {
+	var reminder models.Reminder
+	err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&reminder).Error
+	return &reminder, err
+}
+
+// Обновление напоминания
+func (r *ReminderRepository) Update(reminder *models.Reminder) error {
+	return database.DB.Save(reminder).Error
+}
+
+// Удаление напоминания
+func (r *ReminderRepository) Delete(id uint) error {
+	return database.DB.Delete(&models.Reminder{}, id).Error
+}
+
+// DeleteByTask удаляет все напоминания задачи
+func (r *ReminderRepository) DeleteByTask(taskID uint) error {
+	return database.DB.Where("task_id = ?", taskID).Delete(&models.Reminder{}).Error
+}
+
+// ListDue неотправленные напоминания, время которых наступило
+func (r *ReminderRepository) ListDue(now time.Time, limit int) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	err := database.DB.Where("sent_at IS NULL AND disarmed = ? AND remind_at <= ?", false, now).
+		Order("remind_at asc").
+		Limit(limit).
+		Find(&reminders).Error
+	return reminders, err
+}
+
+// MarkSent атомарно помечает напоминание отправленным.
+// false - его уже забрал кто-то другой.
+func (r *ReminderRepository) MarkSent(id uint, at time.Time) (bool, error) {
+	result := database.DB.Model(&models.Reminder{}).
+		Where("id = ? AND sent_at IS NULL", id).
+		Update("sent_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+// RescheduleRelative пересчитывает относительные напоминания задачи под новый срок.
+// Если срок убрали - неотправленные напоминания выключаются до появления нового срока.
+// Уже сработавшее напоминание снова ждёт отправки, если по новому сроку его время ещё впереди.
+func (r *ReminderRepository) RescheduleRelative(taskID uint, dueAt *time.Time) error {
+	relative := database.DB.Model(&models.Reminder{}).Where("task_id = ? AND offset_seconds IS NOT NULL", taskID)
+	if dueAt == nil {
+		return relative.Where("sent_at IS NULL").Update("disarmed", true).Error
+	}
+
+	var reminders []models.Reminder
+	if err := relative.Find(&reminders).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, reminder := range reminders {
+		remindAt := dueAt.Add(-time.Duration(*reminder.OffsetSeconds) * time.Second)
+		updates := map[string]interface{}{"remind_at": remindAt, "disarmed": false}
+		if reminder.SentAt != nil && remindAt.After(now) {
+			updates["sent_at"] = nil
+		}
+		if err := database.DB.Model(&models.Reminder{}).Where("id = ?", reminder.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
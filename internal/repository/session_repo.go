@@ -0,0 +1,91 @@
+// internal/repository/session_repo.go
+package repository
+
+import (
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepository struct{}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{}
+}
+
+// Создание сессии
+func (r *SessionRepository) Create(session *models.Session) error {
+	return database.DB.Create(session).Error
+}
+
+// GetByID сессия по ID
+func (r *SessionRepository) GetByID(id string) (*models.Session, error) {
+	var session models.Session
+	err := database.DB.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActive действующие сессии пользователя (последняя активность сверху)
+func (r *SessionRepository) ListActive(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at desc").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch обновляет время последней активности и IP
+func (r *SessionRepository) Touch(id, ip string, at time.Time) error {
+	return database.DB.Model(&models.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_seen_at": at,
+			"ip":           ip,
+		}).Error
+}
+
+// Extend продлевает сессию (при обновлении refresh-токена)
+func (r *SessionRepository) Extend(id string, expiresAt time.Time) error {
+	return database.DB.Model(&models.Session{}).Where("id = ?", id).Update("expires_at", expiresAt).Error
+}
+
+// Revoke отзывает одну сессию пользователя (false - не найдена)
+func (r *SessionRepository) Revoke(userID uint, id string) (bool, error) {
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeAllForUser отзывает все сессии пользователя, кроме exceptID (если задан)
+func (r *SessionRepository) RevokeAllForUser(userID uint, exceptID string) error {
+	query := database.DB.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptID != "" {
+		query = query.Where("id <> ?", exceptID)
+	}
+	return query.Update("revoked_at", time.Now()).Error
+}
+
+// RevokeToken добавляет jti в список отозванных
+func (r *SessionRepository) RevokeToken(jti string, expiresAt time.Time) error {
+	return database.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+// IsTokenRevoked проверяет jti по списку отозванных
+func (r *SessionRepository) IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteExpiredRevocations чистит список отозванных от уже истёкших токенов
+func (r *SessionRepository) DeleteExpiredRevocations(now time.Time) error {
+	return database.DB.Where("expires_at < ?", now).Delete(&models.RevokedToken{}).Error
+}
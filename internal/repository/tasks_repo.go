@@ -4,6 +4,7 @@ package repository
 import (
 	"taskflow/internal/database"
 	"taskflow/internal/models"
+	"time"
 )
 
 type TaskRepository struct{}
@@ -40,3 +41,23 @@ func (r *TaskRepository) Update(task *models.Task) error {
 func (r *TaskRepository) Delete(taskID uint) error {
 	return database.DB.Delete(&models.Task{}, taskID).Error
 }
+
+// GetOverdue невыполненные задачи с истёкшим сроком
+func (r *TaskRepository) GetOverdue(userID uint, now time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := database.DB.
+		Where("user_id = ? AND completed = ? AND due_at IS NOT NULL AND due_at < ?", userID, false, now).
+		Order("due_at asc").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// GetDueBetween невыполненные задачи со сроком в интервале [from, to)
+func (r *TaskRepository) GetDueBetween(userID uint, from, to time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := database.DB.
+		Where("user_id = ? AND completed = ? AND due_at >= ? AND due_at < ?", userID, false, from, to).
+		Order("due_at asc").
+		Find(&tasks).Error
+	return tasks, err
+}
@@ -0,0 +1,103 @@
+// internal/repository/two_factor_repo.go
+package repository
+
+import (
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository struct{}
+
+func NewTwoFactorRepository() *TwoFactorRepository {
+	return &TwoFactorRepository{}
+}
+
+// SetSecret сохраняет секрет новой (ещё не подтверждённой) настройки
+func (r *TwoFactorRepository) SetSecret(userID uint, secret string) error {
+	return database.DB.Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"totp_secret":    secret,
+			"totp_enabled":   false,
+			"totp_last_step": 0,
+		}).Error
+}
+
+// Enable включает 2FA и заменяет коды восстановления (одной транзакцией)
+func (r *TwoFactorRepository) Enable(userID uint, step int64, codeHashes []string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_enabled":   true,
+				"totp_last_step": step,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// Disable выключает 2FA и удаляет секрет и коды восстановления
+func (r *TwoFactorRepository) Disable(userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_secret":    "",
+				"totp_enabled":   false,
+				"totp_last_step": 0,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes выдаёт новый набор кодов, старые перестают действовать
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]models.RecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+	}
+	return tx.Create(&codes).Error
+}
+
+// ClaimStep атомарно запоминает принятый шаг TOTP (false - код уже использовали)
+func (r *TwoFactorRepository) ClaimStep(userID uint, step int64) (bool, error) {
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseRecoveryCode атомарно гасит код восстановления (false - нет такого неиспользованного)
+func (r *TwoFactorRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := database.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountRecoveryCodes сколько неиспользованных кодов осталось
+func (r *TwoFactorRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -2,7 +2,9 @@
 package repository
 
 import (
+	"crypto/subtle"
 	"errors"
+	"strings"
 	"taskflow/internal/database"
 	"taskflow/internal/models"
 	"time"
@@ -71,30 +73,41 @@ func (r *UserRepository) SaveVerificationCode(email, code string) error {
 	return database.DB.Model(&models.User{}).
 		Where("email = ?", email).
 		Updates(map[string]interface{}{
-			"verify_code":  code,
-			"code_expires": time.Now().Add(15 * time.Minute),
-			"is_verified":  false,
+			"verify_code":     code,
+			"code_expires":    time.Now().Add(15 * time.Minute),
+			"verify_attempts": 0,
+			"is_verified":     false,
 		}).Error
 }
 
-// VerifyUser проверяет код и активирует пользователя
+// VerifyUser проверяет код и активирует пользователя.
+// Неверный код засчитывается; после models.MaxVerifyAttempts код сгорает.
 func (r *UserRepository) VerifyUser(email, code string) (bool, error) {
 	var user models.User
-	err := database.DB.Where("email = ? AND verify_code = ? AND code_expires > ?",
-		email, code, time.Now()).First(&user).Error
+	err := database.DB.Where("email = ? AND verify_code <> '' AND code_expires > ?",
+		email, time.Now()).First(&user).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, nil // код неверный или истёк
+			return false, nil // кода нет или истёк
 		}
 		return false, err
 	}
 
+	if subtle.ConstantTimeCompare([]byte(user.VerifyCode), []byte(code)) != 1 {
+		updates := map[string]interface{}{"verify_attempts": gorm.Expr("verify_attempts + 1")}
+		if user.VerifyAttempts+1 >= models.MaxVerifyAttempts {
+			updates["verify_code"] = ""
+		}
+		return false, database.DB.Model(&user).Updates(updates).Error
+	}
+
 	// Активируем пользователя
 	err = database.DB.Model(&user).Updates(map[string]interface{}{
-		"is_verified":  true,
-		"verify_code":  "",
-		"code_expires": nil,
+		"is_verified":     true,
+		"verify_code":     "",
+		"verify_attempts": 0,
+		"code_expires":    nil,
 	}).Error
 
 	return true, err
@@ -124,3 +137,139 @@ func (r *UserRepository) ClearExpiredCodes() error {
 func (r *UserRepository) Delete(id uint) error {
 	return database.DB.Delete(&models.User{}, id).Error
 }
+
+// ListVerified все подтверждённые пользователи (для фоновых рассылок)
+func (r *UserRepository) ListVerified() ([]models.User, error) {
+	var users []models.User
+	err := database.DB.Where("is_verified = ?", true).Find(&users).Error
+	return users, err
+}
+
+// UpdateNotificationSettings сохраняет часовой пояс и час отправки сводки
+func (r *UserRepository) UpdateNotificationSettings(id uint, timezone string, digestHour int) error {
+	return database.DB.Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"timezone":    timezone,
+			"digest_hour": digestHour,
+		}).Error
+}
+
+// SetLastDigestAt запоминает время последней отправленной сводки
+func (r *UserRepository) SetLastDigestAt(id uint, at time.Time) error {
+	return database.DB.Model(&models.User{}).Where("id = ?", id).Update("last_digest_at", at).Error
+}
+
+// UpdatePassword сохраняет новый хеш пароля
+func (r *UserRepository) UpdatePassword(id uint, hash string) error {
+	return database.DB.Model(&models.User{}).Where("id = ?", id).Update("password", hash).Error
+}
+
+// UpdateProfile сохраняет имя, фамилию и язык писем
+func (r *UserRepository) UpdateProfile(id uint, firstName, lastName, locale string) error {
+	return database.DB.Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"first_name": firstName,
+			"last_name":  lastName,
+			"locale":     locale,
+		}).Error
+}
+
+// UpdateEmail меняет адрес (уникальность гарантирует индекс)
+func (r *UserRepository) UpdateEmail(id uint, email string) error {
+	return database.DB.Model(&models.User{}).Where("id = ?", id).Update("email", email).Error
+}
+
+// UserFilter условия поиска пользователей в админке
+type UserFilter struct {
+	Query  string // подстрока email, имени или фамилии
+	Role   string
+	Status string // active | disabled | unverified
+}
+
+// Search пользователи по фильтру (новые сверху) и общее количество найденных
+func (r *UserRepository) Search(filter UserFilter, limit, offset int) ([]models.User, int64, error) {
+	query := database.DB.Model(&models.User{})
+	if filter.Query != "" {
+		like := "%" + strings.ToLower(filter.Query) + "%"
+		query = query.Where("LOWER(email) LIKE ? OR LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ?", like, like, like)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	switch filter.Status {
+	case "active":
+		query = query.Where("disabled_at IS NULL AND is_verified = ?", true)
+	case "disabled":
+		query = query.Where("disabled_at IS NOT NULL")
+	case "unverified":
+		query = query.Where("is_verified = ?", false)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := query.Order("id desc").Limit(limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+// SetDisabled блокирует (at - время блокировки) или разблокирует (nil) пользователя
+func (r *UserRepository) SetDisabled(id uint, at *time.Time) error {
+	return database.DB.Model(&models.User{}).Where("id = ?", id).Update("disabled_at", at).Error
+}
+
+// ForceVerify подтверждает email без кода (решение администратора)
+func (r *UserRepository) ForceVerify(id uint) error {
+	return database.DB.Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_verified":     true,
+			"verify_code":     "",
+			"verify_attempts": 0,
+		}).Error
+}
+
+// SetRole меняет роль пользователя
+func (r *UserRepository) SetRole(id uint, role string) error {
+	return database.DB.Model(&models.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+// PromoteAdmins назначает администраторами пользователей с указанными email.
+// Только подтверждённых: иначе адрес из списка мог бы заранее занять кто угодно.
+func (r *UserRepository) PromoteAdmins(emails []string) (int64, error) {
+	if len(emails) == 0 {
+		return 0, nil
+	}
+	result := database.DB.Model(&models.User{}).
+		Where("email IN ? AND is_verified = ? AND role <> ?", emails, true, models.RoleAdmin).
+		Update("role", models.RoleAdmin)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteWithData удаляет пользователя вместе со всеми его данными
+func (r *UserRepository) DeleteWithData(id uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		owned := []interface{}{
+			&models.Reminder{},
+			&models.Task{},
+			&models.Notification{},
+			&models.NotificationPreference{},
+			&models.RefreshToken{},
+			&models.Session{},
+			&models.OneTimeToken{},
+			&models.RecoveryCode{},
+			&models.APIToken{},
+			&models.ExternalIdentity{},
+		}
+		for _, model := range owned {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.User{}, id).Error
+	})
+}
@@ -6,18 +6,35 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 
+	"taskflow/internal/apitoken"
+	"taskflow/internal/audit"
+	"taskflow/internal/auth"
+	"taskflow/internal/bruteforce"
 	"taskflow/internal/email"
+	"taskflow/internal/events"
 	"taskflow/internal/handlers"
 	"taskflow/internal/middleware"
+	"taskflow/internal/models"
+	"taskflow/internal/notify"
+	"taskflow/internal/oidc"
+	"taskflow/internal/outbox"
+	"taskflow/internal/password"
+	"taskflow/internal/reminder"
 	"taskflow/internal/paths"
+	"taskflow/internal/realtime"
 	"taskflow/internal/config"
 	"taskflow/internal/repository"
+	"taskflow/internal/session"
+	"taskflow/internal/twofactor"
+	"taskflow/internal/constants"
+	"taskflow/internal/cookies"
 	prettyprint "taskflow/pkg/pretty_print"
 	"taskflow/internal/database"
 )
@@ -27,11 +44,23 @@ type Server struct {
 	config       *config.ServerConfig
 	appConfig    *config.AppConfig
 	emailService *email.Service
+	mailQueue    *outbox.Queue
+	hub          *events.Hub
+	realtime     *realtime.Hub
+	rateLimits   middleware.RateLimitStore // nil - ограничения выключены
+	cookies      *cookies.Policy
 	testEmail    string
 	http         *http.Server
+	workers      []worker
 }
 
-func New(cfg *config.AppConfig, emailService *email.Service) *Server {
+// worker фоновая задача, живущая столько же, сколько сервер
+type worker struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+func New(cfg *config.AppConfig, emailService *email.Service, mailQueue *outbox.Queue) *Server {
 	// Устанавливаем режим Gin в зависимости от окружения
 	if cfg.IsProd() {
 		gin.SetMode(gin.ReleaseMode)
@@ -43,24 +72,43 @@ func New(cfg *config.AppConfig, emailService *email.Service) *Server {
 
 	r := gin.New()
 	r.Use(gin.Recovery())
+	// По умолчанию gin верит X-Forwarded-For от кого угодно - тогда c.ClientIP() задаёт сам клиент
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		prettyprint.Fatal("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Добавляем middleware безопасности
 	allowedOrigin := getEnv("ALLOWED_ORIGIN", "http://localhost:8080")
 	r.Use(middleware.CORSMiddleware(cfg.IsProd(), allowedOrigin))
 	r.Use(middleware.SecurityHeaders(cfg.IsProd()))
+	jar := cookies.NewPolicy(cfg.Cookie)
+	r.Use(middleware.IssueCSRFToken(jar))
 
-	// Rate limiting (10 запросов в секунду, burst 20)
-	r.Use(middleware.RateLimiter(rate.Limit(10), 20))
+	// Rate limiting: общий лимит на IP, строже - на отдельных группах (см. setupRoutes)
+	var rateLimits middleware.RateLimitStore
+	if cfg.RateLimit.Enabled {
+		rateLimits = middleware.NewMemoryRateLimitStore(cfg.RateLimit.MaxKeys)
+	}
+	r.Use(middleware.RateLimit(rateLimits, rateLimitPolicy("global", cfg.RateLimit.Global)))
 
 	return &Server{
 		router:       r,
+		rateLimits:   rateLimits,
+		cookies:      jar,
 		config:       &cfg.Server,
 		appConfig:    cfg,
 		emailService: emailService,
+		mailQueue:    mailQueue,
+		hub:          events.NewHub(1000),
 		testEmail:    cfg.Email.TestEmail,
 	}
 }
 
+// rateLimitPolicy политика middleware из правила конфигурации
+func rateLimitPolicy(name string, rule config.RateLimitRule) middleware.RateLimitPolicy {
+	return middleware.RateLimitPolicy{Name: name, Requests: rule.Requests, Window: rule.Window}
+}
+
 // getEnv читает переменную окружения
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -108,31 +156,174 @@ func (s *Server) setupStaticFiles() error {
 func (s *Server) setupRoutes() error {
 	userRepo := repository.NewUserRepository()
 	taskRepo := repository.NewTaskRepository()
-	authHandler := handlers.NewAuthHandler(userRepo, s.emailService, s.emailService.TestEmail)
-	taskHandler := handlers.NewTaskHandler(userRepo, taskRepo)
+	notificationRepo := repository.NewNotificationRepository()
+	prefRepo := repository.NewNotificationPreferenceRepository()
+	reminderRepo := repository.NewReminderRepository()
+	refreshRepo := repository.NewRefreshTokenRepository()
+	sessionRepo := repository.NewSessionRepository()
+	oneTimeTokenRepo := repository.NewOneTimeTokenRepository()
+	twoFactorRepo := repository.NewTwoFactorRepository()
+	apiTokenRepo := repository.NewAPITokenRepository()
+	identityRepo := repository.NewExternalIdentityRepository()
+	auditRepo := repository.NewAuditRepository()
+	outboxRepo := repository.NewOutboxRepository()
+	s.addWorker("email outbox", s.mailQueue.Run)
+	if retention := s.appConfig.Audit.Retention; retention > 0 {
+		s.addWorker("audit retention", audit.NewRetention(auditRepo, retention).Run)
+	}
+
+	sessions := session.NewManager(sessionRepo, refreshRepo, userRepo)
+	s.addWorker("revoked token cleanup", sessions.RunCleanup)
+	apiTokens := apitoken.NewService(apiTokenRepo)
+	requireAuth := middleware.AuthMiddleware(sessions, apiTokens, s.cookies)
+	twoFactor := twofactor.NewService(twoFactorRepo, constants.AppName)
+	guard := bruteforce.NewGuard(bruteforce.DefaultAccountPolicy, bruteforce.DefaultIPPolicy)
+	s.addWorker("brute-force counters cleanup", guard.Run)
+
+	passwordPolicy := password.NewPolicy(s.appConfig.Password, auth.MaxPasswordBytes())
+
+	notifier := notify.NewService(notificationRepo, prefRepo, userRepo, s.emailService, s.hub)
+	digestBuilder := notify.NewDigestBuilder(notifier, notificationRepo, taskRepo)
+	s.addWorker("digest scheduler", notify.NewDigestScheduler(digestBuilder, userRepo, s.emailService).Run)
+	s.addWorker("reminder scheduler", reminder.NewScheduler(reminderRepo, taskRepo, notifier).Run)
+
+	authHandler := handlers.NewAuthHandler(userRepo, oneTimeTokenRepo, sessions, s.cookies, twoFactor, guard, s.emailService, notifier, auditRepo, passwordPolicy, s.emailService.TestEmail)
+	taskHandler := handlers.NewTaskHandler(userRepo, taskRepo, reminderRepo, notifier, s.hub)
+	notificationHandler := handlers.NewNotificationHandler(notificationRepo, prefRepo, userRepo, notifier)
+	reminderHandler := handlers.NewReminderHandler(reminderRepo, taskRepo)
+	eventsHandler := handlers.NewEventsHandler(s.hub)
+	sessionHandler := handlers.NewSessionHandler(sessions, s.cookies, auditRepo)
+	passwordHandler := handlers.NewPasswordHandler(userRepo, oneTimeTokenRepo, sessions, s.cookies, apiTokens, guard, s.emailService, notifier, auditRepo, passwordPolicy)
+	accountHandler := handlers.NewAccountHandler(userRepo, oneTimeTokenRepo, sessions, guard, s.emailService, notifier, auditRepo, passwordPolicy)
+	twoFactorHandler := handlers.NewTwoFactorHandler(userRepo, twoFactor, guard, notifier, auditRepo)
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokens, auditRepo)
+	adminHandler := handlers.NewAdminHandler(userRepo, auditRepo, outboxRepo, s.mailQueue, sessions, apiTokens, passwordHandler)
+	oidcHandler := handlers.NewOIDCHandler(s.setupOIDC(), identityRepo, userRepo, oneTimeTokenRepo, s.cookies, authHandler, notifier)
+
+	s.realtime = realtime.NewHub(s.hub, taskRepo)
+	realtimeHandler := handlers.NewRealtimeHandler(s.realtime, userRepo, getEnv("ALLOWED_ORIGIN", "http://localhost:8080"))
 
 	// Страницы
 	s.router.GET("/", handlers.MainPage)
 	s.router.GET("/login", handlers.LoginPage)
-	s.router.GET("/tasks", middleware.AuthMiddleware(), taskHandler.TasksPage)
+	s.router.GET("/forgot-password", handlers.ForgotPasswordPage)
+	s.router.GET("/tasks", requireAuth, taskHandler.TasksPage)
+	s.router.GET("/.well-known/jwks.json", handlers.JWKS)
+
+	// Предпросмотр писем - только в режиме разработки
+	if !s.appConfig.IsProd() {
+		emailPreviewHandler := handlers.NewEmailPreviewHandler(s.emailService)
+		s.router.GET("/dev/emails", emailPreviewHandler.List)
+		s.router.GET("/dev/emails/:name", emailPreviewHandler.Preview)
+	}
 
 	// API группа
+	limits := s.appConfig.RateLimit
+	authLimit := middleware.RateLimit(s.rateLimits, rateLimitPolicy("auth", limits.Auth))
+	// Изменяющие запросы, авторизованные cookie, должны нести X-CSRF-Token
+	requireCSRF := middleware.RequireCSRF(s.cookies)
+
 	api := s.router.Group("/api/v1")
 	{
-		api.POST("/register", authHandler.Register)
-		api.POST("/login", authHandler.Login)
-		api.GET("/logout", authHandler.Logout)
-		api.POST("/verify", authHandler.Verify)
-		api.POST("/resend-code", authHandler.ResendCode)
+		// Всё, что принимает пароль или код, - под строгим лимитом
+		api.POST("/register", authLimit, authHandler.Register)
+		api.POST("/login", authLimit, authHandler.Login)
+		api.POST("/login/2fa", authLimit, authHandler.LoginTwoFactor)
+		api.POST("/login/magic", authLimit, authHandler.MagicLink)
+		api.POST("/login/magic/verify", authLimit, authHandler.MagicLogin)
+		api.POST("/logout", requireCSRF, authHandler.Logout)
+		api.POST("/verify", authLimit, authHandler.Verify)
+		api.POST("/resend-code", authLimit, authHandler.ResendCode)
+		api.POST("/token/refresh", requireCSRF, authHandler.RefreshToken)
+		api.GET("/password/policy", passwordHandler.Policy)
+		api.POST("/password/forgot", authLimit, passwordHandler.Forgot)
+		api.POST("/password/reset", authLimit, passwordHandler.Reset)
+
+		// Вход через внешних провайдеров (OpenID Connect)
+		api.GET("/oidc/providers", oidcHandler.GetProviders)
+		api.GET("/oidc/:provider/login", authLimit, oidcHandler.Login)
+		api.GET("/oidc/:provider/callback", authLimit, oidcHandler.Callback)
+		api.POST("/oidc/exchange", authLimit, oidcHandler.Exchange)
 
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware())
+		// лимит после авторизации - считаем по пользователю, а не по IP
+		protected.Use(requireAuth, requireCSRF, middleware.RateLimitReadWrite(
+			s.rateLimits,
+			rateLimitPolicy("read", limits.Read),
+			rateLimitPolicy("write", limits.Write),
+		))
 		{
-			protected.GET("/tasks", taskHandler.GetTasks)
-			protected.POST("/tasks", taskHandler.CreateTask)
-			protected.PATCH("/tasks/:id", taskHandler.UpdateTask)
-			protected.PUT("/tasks/:id/toggle", taskHandler.ToggleTask)
-			protected.DELETE("/tasks/:id", taskHandler.DeleteTask)
+			// Персональные токены ходят только туда, куда пускают их scopes
+			tasksRead := middleware.RequireScope(models.ScopeTasksRead)
+			tasksWrite := middleware.RequireScope(models.ScopeTasksWrite)
+			notificationsRead := middleware.RequireScope(models.ScopeNotificationsRead)
+			notificationsWrite := middleware.RequireScope(models.ScopeNotificationsWrite)
+
+			protected.GET("/tasks", tasksRead, taskHandler.GetTasks)
+			protected.POST("/tasks", tasksWrite, taskHandler.CreateTask)
+			protected.PATCH("/tasks/:id", tasksWrite, taskHandler.UpdateTask)
+			protected.PUT("/tasks/:id/toggle", tasksWrite, taskHandler.ToggleTask)
+			protected.DELETE("/tasks/:id", tasksWrite, taskHandler.DeleteTask)
+
+			protected.GET("/tasks/:id/reminders", tasksRead, reminderHandler.GetReminders)
+			protected.POST("/tasks/:id/reminders", tasksWrite, reminderHandler.CreateReminder)
+			protected.DELETE("/reminders/:id", tasksWrite, reminderHandler.DeleteReminder)
+			protected.POST("/reminders/:id/snooze", tasksWrite, reminderHandler.SnoozeReminder)
+
+			protected.GET("/events", tasksRead, eventsHandler.Stream)
+			protected.GET("/ws", tasksRead, realtimeHandler.Connect)
+
+			protected.GET("/notifications", notificationsRead, notificationHandler.GetNotifications)
+			protected.POST("/notifications/read-all", notificationsWrite, notificationHandler.MarkAllRead)
+			protected.GET("/notifications/preferences", notificationsRead, notificationHandler.GetPreferences)
+			protected.PUT("/notifications/preferences", notificationsWrite, notificationHandler.UpdatePreferences)
+			protected.POST("/notifications/:id/read", notificationsWrite, notificationHandler.MarkRead)
+		}
+
+		// Аккаунт, сессии и токены - только из приложения, не персональным токеном
+		// и не администратором, вошедшим от имени пользователя
+		account := protected.Group("/", middleware.RequireSession(), middleware.DenyImpersonation())
+		{
+			account.GET("/me", accountHandler.GetMe)
+			account.PATCH("/me", accountHandler.UpdateMe)
+			account.POST("/me/password", accountHandler.ChangePassword)
+			account.POST("/me/email", accountHandler.ChangeEmail)
+			account.POST("/me/email/confirm", accountHandler.ConfirmEmailChange)
+
+			account.GET("/me/2fa", twoFactorHandler.Status)
+			account.POST("/me/2fa/setup", twoFactorHandler.Setup)
+			account.POST("/me/2fa/confirm", twoFactorHandler.Confirm)
+			account.POST("/me/2fa/disable", twoFactorHandler.Disable)
+			account.POST("/me/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+
+			account.GET("/sessions", sessionHandler.GetSessions)
+			account.DELETE("/sessions", sessionHandler.DeleteAllSessions)
+			account.DELETE("/sessions/:id", sessionHandler.DeleteSession)
+
+			account.GET("/tokens", apiTokenHandler.GetTokens)
+			account.POST("/tokens", apiTokenHandler.CreateToken)
+			account.DELETE("/tokens/:id", apiTokenHandler.DeleteToken)
+
+			account.GET("/me/identities", oidcHandler.GetIdentities)
+			account.DELETE("/me/identities/:id", oidcHandler.DeleteIdentity)
+		}
+
+		// Управление пользователями - только администраторам
+		admin := account.Group("/admin", middleware.RequireAdmin(userRepo))
+		{
+			admin.GET("/users", adminHandler.ListUsers)
+			admin.GET("/users/:id", adminHandler.GetUser)
+			admin.POST("/users/:id/disable", adminHandler.DisableUser)
+			admin.POST("/users/:id/enable", adminHandler.EnableUser)
+			admin.POST("/users/:id/verify", adminHandler.VerifyUser)
+			admin.POST("/users/:id/password-reset", adminHandler.ForcePasswordReset)
+			admin.PUT("/users/:id/role", adminHandler.SetRole)
+			admin.DELETE("/users/:id", adminHandler.DeleteUser)
+			admin.POST("/users/:id/impersonate", adminHandler.Impersonate)
+			admin.GET("/audit", adminHandler.ListAudit)
+			admin.GET("/audit/export", adminHandler.ExportAudit)
+			admin.GET("/emails", adminHandler.ListEmails)
+			admin.POST("/emails/:id/retry", adminHandler.RetryEmail)
 		}
 	}
 
@@ -140,11 +331,97 @@ func (s *Server) setupRoutes() error {
 	return nil
 }
 
+// setupOIDC провайдеры внешнего входа из конфигурации.
+// В режиме разработки с OIDC_STUB=true добавляется тестовый провайдер на /dev/oidc.
+func (s *Server) setupOIDC() *oidc.Registry {
+	providers := s.appConfig.OIDC.Providers
+
+	if s.appConfig.OIDC.Stub {
+		if s.appConfig.IsProd() {
+			prettyprint.Warn("OIDC_STUB is ignored in production")
+		} else if stubProvider, ok := s.mountOIDCStub(); ok {
+			providers = append(providers, stubProvider)
+		}
+	}
+
+	return oidc.NewRegistry(providers, s.appConfig.PublicURL+"/api/v1/oidc")
+}
+
+// mountOIDCStub поднимает тестовый провайдер внутри приложения
+func (s *Server) mountOIDCStub() (config.OIDCProvider, bool) {
+	const stubPath = "/dev/oidc"
+
+	secret, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		prettyprint.Error("Failed to start OIDC stub: %v", err)
+		return config.OIDCProvider{}, false
+	}
+	provider := config.OIDCProvider{
+		Name:         "stub",
+		DisplayName:  "Test IdP",
+		Issuer:       strings.TrimRight(s.appConfig.PublicURL, "/") + stubPath,
+		ClientID:     "taskflow-dev",
+		ClientSecret: secret,
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+
+	stub, err := oidc.NewStub(provider.Issuer, provider.ClientID, provider.ClientSecret)
+	if err != nil {
+		prettyprint.Error("Failed to start OIDC stub: %v", err)
+		return config.OIDCProvider{}, false
+	}
+	s.router.Any(stubPath+"/*path", gin.WrapH(http.StripPrefix(stubPath, stub)))
+
+	prettyprint.Warn("OIDC stub provider enabled at %s (development only)", provider.Issuer)
+	return provider, true
+}
+
+// promoteAdmins выдаёт роль администратора пользователям из ADMIN_EMAILS
+func (s *Server) promoteAdmins() {
+	promoted, err := repository.NewUserRepository().PromoteAdmins(s.appConfig.Auth.AdminEmails)
+	if err != nil {
+		prettyprint.Error("Failed to promote admins: %v", err)
+		return
+	}
+	if promoted > 0 {
+		prettyprint.Info("Promoted %d user(s) to admin", promoted)
+	}
+}
+
+// addWorker регистрирует фоновую задачу; запускается в Run после подключения к БД
+func (s *Server) addWorker(name string, run func(ctx context.Context)) {
+	s.workers = append(s.workers, worker{name: name, run: run})
+}
+
+// startWorkers запускает фоновые задачи и возвращает функцию их остановки
+func (s *Server) startWorkers() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	for _, w := range s.workers {
+		wg.Add(1)
+		go func(w worker) {
+			defer wg.Done()
+			w.run(ctx)
+		}(w)
+		prettyprint.Debug("Worker started: %s", w.name)
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
 func (s *Server) Run() error {
 	if err := database.Init(); err != nil {
 		prettyprint.Fatal("Failed to connect to database: %v", err)
 	}
 
+	s.promoteAdmins()
+
+	stopWorkers := s.startWorkers()
+
 	s.http = &http.Server{
 		Addr:         s.config.Port,
 		Handler:      s.router,
@@ -152,6 +429,9 @@ func (s *Server) Run() error {
 		WriteTimeout: s.config.WriteTimeout,
 		IdleTimeout:  s.config.IdleTimeout,
 	}
+	// SSE и WebSocket соединения сами не завершатся - закрываем их в начале Shutdown
+	s.http.RegisterOnShutdown(s.hub.Close)
+	s.http.RegisterOnShutdown(s.realtime.Close)
 
 	// Канал для graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -176,8 +456,15 @@ func (s *Server) Run() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := s.http.Shutdown(ctx); err != nil {
-		return err
+	shutdownErr := s.http.Shutdown(ctx)
+
+	// Фоновые задачи - после HTTP: запросы успевают поставить письма в очередь,
+	// а очередь дожидается уже начатых отправок
+	prettyprint.Progress("Stopping background workers")
+	stopWorkers()
+
+	if shutdownErr != nil {
+		return shutdownErr
 	}
 
 	prettyprint.Success("Server exited gracefully")
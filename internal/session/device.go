@@ -0,0 +1,50 @@
+// internal/session/device.go
+package session
+
+import "strings"
+
+// DeviceName грубо определяет браузер и ОС по User-Agent ("Chrome, Windows").
+// Точность не важна - это подсказка пользователю в списке сессий.
+func DeviceName(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := firstMatch(userAgent, [][2]string{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"YaBrowser/", "Yandex Browser"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+		{"PostmanRuntime/", "Postman"},
+	})
+	os := firstMatch(userAgent, [][2]string{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	})
+
+	switch {
+	case browser != "" && os != "":
+		return browser + ", " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	}
+	return "Unknown device"
+}
+
+func firstMatch(userAgent string, rules [][2]string) string {
+	for _, rule := range rules {
+		if strings.Contains(userAgent, rule[0]) {
+			return rule[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,329 @@
+// internal/session/manager.go
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"taskflow/internal/auth"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+
+	prettyprint "taskflow/pkg/pretty_print"
+)
+
+var (
+	// ErrInvalidRefresh refresh-токен не найден, истёк или отозван
+	ErrInvalidRefresh = errors.New("invalid refresh token")
+	// ErrRefreshReuse повторное использование уже обменянного refresh-токена
+	ErrRefreshReuse = errors.New("refresh token reuse detected")
+	// ErrSessionRevoked сессия завершена (logout, "выйти везде" и т.п.)
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+// lastSeenInterval как часто обновлять last_seen_at (не на каждый запрос)
+const lastSeenInterval = time.Minute
+
+// refreshReuseGrace сколько после ротации старый refresh-токен ещё можно предъявить:
+// параллельные запросы одной вкладки или несколько вкладок после сна ноутбука
+// приходят с одной и той же cookie, и это не кража
+const refreshReuseGrace = 10 * time.Second
+
+// Tokens то, что получает клиент при входе или обновлении
+type Tokens struct {
+	Access    string
+	Refresh   string
+	SessionID string
+	ExpiresIn int
+}
+
+// Manager серверные сессии: выдача и обновление токенов, проверка, отзыв.
+// Сессия = семейство refresh-токенов; её ID лежит в claim "sid" access-токена.
+type Manager struct {
+	sessions *repository.SessionRepository
+	refresh  *repository.RefreshTokenRepository
+	userRepo *repository.UserRepository
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time // когда последний раз писали last_seen_at
+}
+
+func NewManager(
+	sessions *repository.SessionRepository,
+	refresh *repository.RefreshTokenRepository,
+	userRepo *repository.UserRepository,
+) *Manager {
+	return &Manager{
+		sessions: sessions,
+		refresh:  refresh,
+		userRepo: userRepo,
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+// Start создаёт новую сессию (вход на новом устройстве)
+func (m *Manager) Start(user *models.User, ip, userAgent string) (*Tokens, error) {
+	id, err := auth.NewID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = m.sessions.Create(&models.Session{
+		ID:         id,
+		UserID:     user.ID,
+		Device:     DeviceName(userAgent),
+		IP:         ip,
+		UserAgent:  truncate(userAgent, 500),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(auth.RefreshTokenTTL()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.issue(user, id)
+}
+
+// StartImpersonation сессия администратора adminID от имени пользователя.
+// Refresh-токена нет: сессия живёт, пока жив access-токен, продлить её нельзя.
+func (m *Manager) StartImpersonation(user *models.User, adminID uint, ip, userAgent string) (*Tokens, error) {
+	id, err := auth.NewID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = m.sessions.Create(&models.Session{
+		ID:             id,
+		UserID:         user.ID,
+		Device:         DeviceName(userAgent),
+		IP:             ip,
+		UserAgent:      truncate(userAgent, 500),
+		LastSeenAt:     now,
+		ExpiresAt:      now.Add(auth.AccessTokenTTL()),
+		ImpersonatorID: &adminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := auth.GenerateImpersonationToken(user.ID, user.Email, id, adminID)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		Access:    access,
+		SessionID: id,
+		ExpiresIn: int(auth.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// Refresh обменивает refresh-токен на новую пару в рамках той же сессии.
+// При повторном использовании токена (позже refreshReuseGrace) завершает всю сессию.
+func (m *Manager) Refresh(raw, ip string) (*Tokens, *models.User, error) {
+	hash := auth.HashToken(raw)
+	stored, err := m.refresh.GetByHash(hash)
+	if err != nil || stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, nil, ErrInvalidRefresh
+	}
+
+	claimed, err := m.refresh.MarkUsed(stored.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !claimed {
+		// Перечитываем: токен мог обменять параллельный запрос уже после GetByHash
+		if stored, err = m.refresh.GetByHash(hash); err != nil || stored.RevokedAt != nil {
+			return nil, nil, ErrInvalidRefresh
+		}
+
+		// Токен обменяли давно - его кто-то украл (или клиент завис на старом).
+		// Отзываем всю сессию: и злоумышленнику, и владельцу придётся войти заново.
+		if stored.UsedAt == nil || time.Since(*stored.UsedAt) > refreshReuseGrace {
+			prettyprint.Warn("Refresh token reuse detected: user=%d session=%s ip=%s", stored.UserID, stored.FamilyID, ip)
+			if _, err := m.Revoke(stored.UserID, stored.FamilyID); err != nil {
+				prettyprint.Error("Failed to revoke session %s: %v", stored.FamilyID, err)
+			}
+			return nil, nil, ErrRefreshReuse
+		}
+	}
+
+	session, err := m.sessions.GetByID(stored.FamilyID)
+	if err != nil || session.RevokedAt != nil {
+		return nil, nil, ErrInvalidRefresh
+	}
+
+	user, err := m.userRepo.GetByID(stored.UserID)
+	if err != nil || user.Disabled() {
+		return nil, nil, ErrInvalidRefresh
+	}
+
+	now := time.Now()
+	if err := m.sessions.Extend(session.ID, now.Add(auth.RefreshTokenTTL())); err != nil {
+		return nil, nil, err
+	}
+	m.touch(session.ID, ip, now)
+
+	tokens, err := m.issue(user, session.ID)
+	return tokens, user, err
+}
+
+// Validate проверяет, что access-токен не отозван и его сессия жива.
+// Заодно (не чаще раза в минуту) обновляет время последней активности.
+func (m *Manager) Validate(claims *auth.Claims, ip string) error {
+	if claims.SessionID == "" || claims.ID == "" {
+		// токен выписан до появления серверных сессий
+		return ErrSessionRevoked
+	}
+
+	revoked, err := m.sessions.IsTokenRevoked(claims.ID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrSessionRevoked
+	}
+
+	session, err := m.sessions.GetByID(claims.SessionID)
+	if err != nil || session.RevokedAt != nil || session.UserID != claims.UserID {
+		return ErrSessionRevoked
+	}
+
+	m.touch(session.ID, ip, time.Now())
+	return nil
+}
+
+// List действующие сессии пользователя
+func (m *Manager) List(userID uint) ([]models.Session, error) {
+	return m.sessions.ListActive(userID)
+}
+
+// Revoke завершает сессию: её access-токены перестают приниматься сразу,
+// refresh-токены отзываются. false - сессия не найдена или уже завершена.
+func (m *Manager) Revoke(userID uint, sessionID string) (bool, error) {
+	found, err := m.sessions.Revoke(userID, sessionID)
+	if err != nil {
+		return false, err
+	}
+	if err := m.refresh.RevokeFamily(sessionID); err != nil {
+		return found, err
+	}
+	m.forget(sessionID)
+	return found, nil
+}
+
+// RevokeByRefresh завершает сессию, которой принадлежит refresh-токен
+// (logout с уже истёкшим access-токеном). Возвращает владельца, 0 - токен не найден
+func (m *Manager) RevokeByRefresh(raw string) (uint, error) {
+	stored, err := m.refresh.GetByHash(auth.HashToken(raw))
+	if err != nil {
+		return 0, nil
+	}
+	_, err = m.Revoke(stored.UserID, stored.FamilyID)
+	return stored.UserID, err
+}
+
+// RevokeAll завершает все сессии пользователя ("выйти везде").
+// exceptID - сессия, которую нужно оставить (обычно текущая), может быть пустым.
+func (m *Manager) RevokeAll(userID uint, exceptID string) error {
+	if err := m.sessions.RevokeAllForUser(userID, exceptID); err != nil {
+		return err
+	}
+	if exceptID == "" {
+		return m.refresh.RevokeAllForUser(userID)
+	}
+	return m.refresh.RevokeAllForUserExcept(userID, exceptID)
+}
+
+// RevokeAccessToken вносит конкретный access-токен в список отозванных
+func (m *Manager) RevokeAccessToken(claims *auth.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	return m.sessions.RevokeToken(claims.ID, claims.ExpiresAt.Time)
+}
+
+// RunCleanup раз в час удаляет истёкшие записи из списка отозванных токенов
+func (m *Manager) RunCleanup(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := m.sessions.DeleteExpiredRevocations(now); err != nil {
+				prettyprint.Error("Failed to clean up revoked tokens: %v", err)
+			}
+			m.mu.Lock()
+			for id, at := range m.lastSeen {
+				if now.Sub(at) > lastSeenInterval {
+					delete(m.lastSeen, id)
+				}
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+func (m *Manager) issue(user *models.User, sessionID string) (*Tokens, error) {
+	access, err := auth.GenerateToken(user.ID, user.Email, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.refresh.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  sessionID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(auth.RefreshTokenTTL()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		Access:    access,
+		Refresh:   refresh,
+		SessionID: sessionID,
+		ExpiresIn: int(auth.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// touch пишет last_seen_at, если с прошлой записи прошло больше lastSeenInterval
+func (m *Manager) touch(sessionID, ip string, now time.Time) {
+	m.mu.Lock()
+	last, ok := m.lastSeen[sessionID]
+	if ok && now.Sub(last) < lastSeenInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.lastSeen[sessionID] = now
+	m.mu.Unlock()
+
+	if err := m.sessions.Touch(sessionID, ip, now); err != nil {
+		prettyprint.Warn("Failed to update session %s: %v", sessionID, err)
+	}
+}
+
+func (m *Manager) forget(sessionID string) {
+	m.mu.Lock()
+	delete(m.lastSeen, sessionID)
+	m.mu.Unlock()
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
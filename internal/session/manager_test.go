@@ -0,0 +1,131 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taskflow/internal/auth"
+	"taskflow/internal/config"
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+)
+
+func newTestManager(t *testing.T) (*Manager, *models.User) {
+	t.Helper()
+	if err := database.Open(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	err := auth.Init(config.AuthConfig{
+		JWTAlgorithm:    "HS256",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 24 * time.Hour,
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{Email: "session@example.com", Password: "-", IsVerified: true}
+	if err := database.DB.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(repository.NewSessionRepository(), repository.NewRefreshTokenRepository(), repository.NewUserRepository())
+	return m, user
+}
+
+// usedAgo сдвигает время обмена refresh-токена в прошлое
+func usedAgo(t *testing.T, raw string, ago time.Duration) {
+	t.Helper()
+	err := database.DB.Model(&models.RefreshToken{}).
+		Where("token_hash = ?", auth.HashToken(raw)).
+		Update("used_at", time.Now().Add(-ago)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	m, user := newTestManager(t)
+	first, err := m.Start(user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, got, err := m.Refresh(first.Refresh, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got.ID != user.ID || second.SessionID != first.SessionID {
+		t.Fatalf("refresh moved to user %d, session %s", got.ID, second.SessionID)
+	}
+	if second.Refresh == first.Refresh || second.Access == "" {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	if _, _, err := m.Refresh(second.Refresh, "127.0.0.1"); err != nil {
+		t.Fatalf("rotated token: %v", err)
+	}
+	if _, _, err := m.Refresh("unknown-token", "127.0.0.1"); !errors.Is(err, ErrInvalidRefresh) {
+		t.Fatalf("unknown token: err = %v, want ErrInvalidRefresh", err)
+	}
+}
+
+func TestRefreshConcurrentUseWithinGrace(t *testing.T) {
+	m, user := newTestManager(t)
+	first, err := m.Start(user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Две вкладки обновляют сессию одной и той же cookie почти одновременно
+	a, _, err := m.Refresh(first.Refresh, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _, err := m.Refresh(first.Refresh, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("second refresh within grace: %v", err)
+	}
+	if b.SessionID != first.SessionID {
+		t.Fatalf("session = %s, want %s", b.SessionID, first.SessionID)
+	}
+
+	// Сессия жива: оба новых токена работают
+	for name, tokens := range map[string]*Tokens{"first tab": a, "second tab": b} {
+		if _, _, err := m.Refresh(tokens.Refresh, "127.0.0.1"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	m, user := newTestManager(t)
+	first, err := m.Start(user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := m.Refresh(first.Refresh, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Старый токен предъявили уже после окна: это кража
+	usedAgo(t, first.Refresh, refreshReuseGrace+time.Second)
+	if _, _, err := m.Refresh(first.Refresh, "10.0.0.66"); !errors.Is(err, ErrRefreshReuse) {
+		t.Fatalf("reuse: err = %v, want ErrRefreshReuse", err)
+	}
+
+	// Отозвана вся сессия, в том числе токен законного владельца
+	if _, _, err := m.Refresh(second.Refresh, "127.0.0.1"); !errors.Is(err, ErrInvalidRefresh) {
+		t.Fatalf("owner token after reuse: err = %v, want ErrInvalidRefresh", err)
+	}
+	claims, err := auth.ValidateToken(second.Access)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Validate(claims, "127.0.0.1"); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("access token after reuse: err = %v, want ErrSessionRevoked", err)
+	}
+}
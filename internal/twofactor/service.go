@@ -0,0 +1,155 @@
+// internal/twofactor/service.go
+package twofactor
+
+import (
+	"errors"
+	"time"
+
+	"taskflow/internal/auth"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+)
+
+// recoveryCodeCount сколько кодов восстановления выдаётся за раз
+const recoveryCodeCount = 10
+
+var (
+	ErrNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrNotSetUp       = errors.New("two-factor setup was not started")
+	ErrInvalidCode    = errors.New("invalid two-factor code")
+)
+
+// Service включение/выключение TOTP и проверка второго фактора
+type Service struct {
+	repo   *repository.TwoFactorRepository
+	issuer string
+}
+
+func NewService(repo *repository.TwoFactorRepository, issuer string) *Service {
+	return &Service{repo: repo, issuer: issuer}
+}
+
+// Setup начинает настройку: новый секрет и otpauth-ссылка для приложения.
+// Пока пользователь не подтвердит кодом, 2FA не включена.
+func (s *Service) Setup(user *models.User) (*models.TwoFactorSetupResponse, error) {
+	if user.TOTPEnabled {
+		return nil, ErrAlreadyEnabled
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetSecret(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(secret, user.Email, s.issuer),
+	}, nil
+}
+
+// Confirm включает 2FA по первому коду из приложения и возвращает коды восстановления
+func (s *Service) Confirm(user *models.User, code string) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, ErrAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrNotSetUp
+	}
+
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Enable(user.ID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify проверяет второй фактор: код из приложения или код восстановления.
+// Каждый код принимается один раз.
+func (s *Service) Verify(user *models.User, code string) error {
+	if !user.TOTPEnabled {
+		return ErrNotEnabled
+	}
+
+	if step, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		claimed, err := s.repo.ClaimStep(user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(user.ID, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// Disable выключает 2FA (проверку пароля и кода делает вызывающий)
+func (s *Service) Disable(user *models.User) error {
+	if !user.TOTPEnabled {
+		return ErrNotEnabled
+	}
+	return s.repo.Disable(user.ID)
+}
+
+// RegenerateRecoveryCodes выдаёт новый набор кодов восстановления
+func (s *Service) RegenerateRecoveryCodes(user *models.User) ([]string, error) {
+	if !user.TOTPEnabled {
+		return nil, ErrNotEnabled
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Status включена ли 2FA и сколько осталось кодов восстановления
+func (s *Service) Status(user *models.User) (*models.TwoFactorStatusResponse, error) {
+	status := &models.TwoFactorStatusResponse{Enabled: user.TOTPEnabled}
+	if !user.TOTPEnabled {
+		return status, nil
+	}
+
+	left, err := s.repo.CountRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	status.RecoveryCodesLeft = left
+	return status, nil
+}
+
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	codes = make([]string, recoveryCodeCount)
+	hashes = make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = auth.NewRecoveryCode(); err != nil {
+			return nil, nil, err
+		}
+		hashes[i] = auth.HashToken(codes[i])
+	}
+	return codes, hashes, nil
+}
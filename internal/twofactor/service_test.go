@@ -0,0 +1,148 @@
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"taskflow/internal/database"
+	"taskflow/internal/models"
+	"taskflow/internal/repository"
+)
+
+func newTestService(t *testing.T) (*Service, *models.User) {
+	t.Helper()
+	if err := database.Open(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Email: "2fa@example.com", Password: "-", IsVerified: true}
+	if err := database.DB.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewService(repository.NewTwoFactorRepository(), "TaskFlow"), user
+}
+
+// reload пользователь из базы - как его видят обработчики при следующем запросе
+func reload(t *testing.T, user *models.User) *models.User {
+	t.Helper()
+	var fresh models.User
+	if err := database.DB.First(&fresh, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &fresh
+}
+
+// currentCode код из "приложения-аутентификатора": HOTP (RFC 4226) для текущего шага
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+// enable проходит настройку целиком и возвращает включённого пользователя, код подтверждения и коды восстановления
+func enable(t *testing.T, svc *Service, user *models.User) (*models.User, string, []string) {
+	t.Helper()
+	setup, err := svc.Setup(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := currentCode(t, setup.Secret)
+	recovery, err := svc.Confirm(reload(t, user), code)
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if len(recovery) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(recovery), recoveryCodeCount)
+	}
+	return reload(t, user), code, recovery
+}
+
+func TestVerifyRejectsReplayedTOTP(t *testing.T) {
+	svc, user := newTestService(t)
+	user, confirmCode, _ := enable(t, svc, user)
+
+	if user.TOTPLastStep == 0 {
+		t.Fatal("Confirm did not store TOTPLastStep")
+	}
+	// Код, которым подтвердили настройку, уже использован
+	if err := svc.Verify(user, confirmCode); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("replayed confirm code: err = %v, want ErrInvalidCode", err)
+	}
+
+	// Свежий шаг принимается один раз: откатываем сохранённый шаг, как будто прошло время
+	if err := database.DB.Model(&models.User{}).Where("id = ?", user.ID).
+		Update("totp_last_step", user.TOTPLastStep-2).Error; err != nil {
+		t.Fatal(err)
+	}
+	code := currentCode(t, user.TOTPSecret)
+	if err := svc.Verify(user, code); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := svc.Verify(user, code); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("second use: err = %v, want ErrInvalidCode", err)
+	}
+	if got := reload(t, user).TOTPLastStep; got < user.TOTPLastStep {
+		t.Fatalf("TOTPLastStep went back: %d < %d", got, user.TOTPLastStep)
+	}
+}
+
+func TestRecoveryCodesAreSingleUse(t *testing.T) {
+	svc, user := newTestService(t)
+	user, _, recovery := enable(t, svc, user)
+
+	if err := svc.Verify(user, recovery[0]); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := svc.Verify(user, recovery[0]); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("second use: err = %v, want ErrInvalidCode", err)
+	}
+
+	// Код можно ввести без дефиса и заглавными
+	typed := strings.ToUpper(strings.ReplaceAll(recovery[1], "-", ""))
+	if err := svc.Verify(user, typed); err != nil {
+		t.Fatalf("normalized code: %v", err)
+	}
+
+	status, err := svc.Status(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(recoveryCodeCount - 2); status.RecoveryCodesLeft != want {
+		t.Fatalf("RecoveryCodesLeft = %d, want %d", status.RecoveryCodesLeft, want)
+	}
+
+	// Новый набор гасит старые коды
+	fresh, err := svc.RegenerateRecoveryCodes(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Verify(user, recovery[2]); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("old code after regenerate: err = %v, want ErrInvalidCode", err)
+	}
+	if err := svc.Verify(user, fresh[0]); err != nil {
+		t.Fatalf("new code after regenerate: %v", err)
+	}
+}
+
+func TestVerifyRequiresEnabled(t *testing.T) {
+	svc, user := newTestService(t)
+	if err := svc.Verify(user, "123456"); !errors.Is(err, ErrNotEnabled) {
+		t.Fatalf("err = %v, want ErrNotEnabled", err)
+	}
+}
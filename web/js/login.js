@@ -2,6 +2,8 @@
 
 // ========== ГЛОБАЛЬНЫЕ ПЕРЕМЕННЫЕ ==========
 let currentEmail = '';
+let challengeToken = ''; // токен второго шага входа (2FA)
+let magicEmail = ''; // куда отправлена ссылка для входа без пароля
 
 // ========== ИНИЦИАЛИЗАЦИЯ ПРИ ЗАГРУЗКЕ ==========
 document.addEventListener('DOMContentLoaded', () => {
@@ -13,9 +15,17 @@ document.addEventListener('DOMContentLoaded', () => {
     }
 
     if (registerForm) {
+        applyPasswordPolicy('register-password');
         registerForm.addEventListener('submit', handleRegister);
     }
 
+    document.getElementById('two-factor-form')?.addEventListener('submit', handleTwoFactor);
+    document.getElementById('magic-form')?.addEventListener('submit', handleMagicCode);
+    document.getElementById('magic-link')?.addEventListener('click', (e) => {
+        e.preventDefault();
+        requestMagicLink();
+    });
+
     // Инициализируем обработчики модального окна
     setupCodeInputs();
 
@@ -34,28 +44,80 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     });
 
-    const token = localStorage.getItem('token');
-    const currentPath = window.location.pathname;
+    loadOIDCProviders();
 
-    // Если есть токен и мы на логине - редирект на задачи
-    if (token && currentPath === '/login') {
-        const hasCookie = document.cookie.includes('token=');
+    // Переход по ссылке из письма: /login?magic=...
+    const params = new URLSearchParams(window.location.search);
+    const magicToken = params.get('magic');
+    if (magicToken) {
+        history.replaceState(null, '', '/login');
+        redeemMagicLink({ token: magicToken }, 'login-form');
+        return;
+    }
 
-        if (!hasCookie) {
-            document.cookie = `token=${token}; path=/; max-age=86400; samesite=strict`;
+    // Возврат от внешнего провайдера: /login?oidc=... или /login?oidc_error=...
+    if (params.has('oidc') || params.has('oidc_error')) {
+        history.replaceState(null, '', '/login');
+        if (params.get('oidc')) {
+            exchangeOIDCLogin(params.get('oidc'));
+        } else {
+            showMessage('login-form', oidcErrorMessages[params.get('oidc_error')] || oidcErrorMessages.provider_error);
         }
-
-        window.location.href = '/tasks';
         return;
     }
+
+    // Токен раньше хранился здесь - убираем остатки старых версий
+    localStorage.removeItem('token');
+    const wasLoggedIn = localStorage.getItem('user');
+    const currentPath = window.location.pathname;
+
+    // Если раньше входили и мы на логине - пробуем продлить сессию по cookie и уйти к задачам.
+    // Сюда же попадаем с /tasks, когда истёк access-токен в cookie.
+    if (wasLoggedIn && currentPath === '/login') {
+        restoreSession();
+    }
 });
 
+// CSRF-токен из <meta name="csrf-token">: сервер требует его в заголовке
+// у изменяющих запросов, авторизованных cookie
+function csrfHeaders() {
+    const meta = document.querySelector('meta[name="csrf-token"]');
+    return meta && meta.content ? { 'X-CSRF-Token': meta.content } : {};
+}
+
+// Та же блокировка, что и на странице задач: открытая рядом вкладка
+// не должна обновлять сессию одновременно с этой
+function withRefreshLock(fn) {
+    if (navigator.locks) {
+        return navigator.locks.request('taskflow-session-refresh', fn);
+    }
+    return fn();
+}
+
+async function restoreSession() {
+    try {
+        const response = await withRefreshLock(() =>
+            fetch('/api/v1/token/refresh', { method: 'POST', credentials: 'same-origin', headers: csrfHeaders() }));
+        if (response.ok) {
+            window.location.href = '/tasks';
+            return;
+        }
+    } catch (error) {
+        console.log('❌ Не удалось продлить сессию:', error);
+    }
+
+    // Сессия закончилась - остаёмся на странице входа
+    localStorage.removeItem('user');
+}
+
 // ========== ПЕРЕКЛЮЧЕНИЕ ФОРМ ==========
 function switchForm(formName, event) {
     document.querySelectorAll('.tab-btn').forEach(btn => {
         btn.classList.remove('active');
     });
     event.target.classList.add('active');
+    document.getElementById('two-factor-form')?.classList.remove('active');
+    document.getElementById('magic-form')?.classList.remove('active');
 
     if (formName === 'login') {
         document.getElementById('login-form').classList.add('active');
@@ -158,6 +220,7 @@ async function verifyCode() {
 
         const response = await fetch('/api/v1/verify', {
             method: 'POST',
+            credentials: 'same-origin',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({
                 email: currentEmail,
@@ -171,11 +234,10 @@ async function verifyCode() {
         console.log('📦 Данные ответа:', data); // 👈 4. Что вернул сервер
 
         if (response.ok) {
-            console.log('✅ Успех! Сохраняем токен...');
+            console.log('✅ Успех! Входим...');
 
-            localStorage.setItem('token', data.token);
+            // cookie с токенами ставит сервер, сохраняем только профиль
             localStorage.setItem('user', JSON.stringify(data.user));
-            document.cookie = `token=${data.token}; path=/; max-age=86400; samesite=strict`;
 
             document.getElementById('verification-message').className = 'verification-message success';
             document.getElementById('verification-message').textContent = '✓ Email подтверждён!';
@@ -258,6 +320,7 @@ async function handleLogin(e) {
     try {
         const response = await fetch('/api/v1/login', {
             method: 'POST',
+            credentials: 'same-origin',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({
                 email: email,
@@ -268,11 +331,7 @@ async function handleLogin(e) {
         const data = await response.json();
 
         if (response.ok) {
-            localStorage.setItem('token', data.token);
-            localStorage.setItem('user', JSON.stringify(data.user));
-            document.cookie = `token=${data.token}; path=/; max-age=86400; samesite=strict`;
-
-            window.location.href = '/tasks';
+            finishLogin(data, 'login-form');
         } else {
             // 👇 ОСОБАЯ ОБРАБОТКА ДЛЯ НЕВЕРИФИЦИРОВАННЫХ
             if (response.status === 403 && data.email) {
@@ -292,6 +351,176 @@ async function handleLogin(e) {
     }
 }
 
+// finishLogin общий финал входа (пароль или ссылка): сессия или второй шаг 2FA
+function finishLogin(data, formId) {
+    if (data.twoFactorRequired) {
+        // Первый фактор пройден, но нужен код из приложения
+        challengeToken = data.challengeToken;
+        document.getElementById(formId).classList.remove('active');
+        document.getElementById('two-factor-form').classList.add('active');
+        document.getElementById('two-factor-code').focus();
+        return;
+    }
+
+    // cookie с токенами ставит сервер, сохраняем только профиль
+    localStorage.setItem('user', JSON.stringify(data.user));
+
+    window.location.href = '/tasks';
+}
+
+// ========== ВХОД БЕЗ ПАРОЛЯ (ССЫЛКА ИЗ ПИСЬМА) ==========
+async function requestMagicLink() {
+    const email = document.getElementById('login-email').value.trim();
+    if (!isValidEmail(email)) {
+        showMessage('login-form', 'Введите email, чтобы получить ссылку для входа');
+        return;
+    }
+
+    try {
+        const response = await fetch('/api/v1/login/magic', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ email: email })
+        });
+        const data = await response.json();
+
+        if (response.ok) {
+            magicEmail = email;
+            document.getElementById('magic-email').textContent = email;
+            document.getElementById('login-form').classList.remove('active');
+            document.getElementById('magic-form').classList.add('active');
+            document.getElementById('magic-code').focus();
+        } else {
+            showMessage('login-form', data.error || 'Ошибка сервера');
+        }
+    } catch (error) {
+        showMessage('login-form', 'Ошибка соединения с сервером');
+    }
+}
+
+async function handleMagicCode(e) {
+    e.preventDefault();
+
+    const form = e.target;
+    const submitBtn = form.querySelector('button[type="submit"]');
+    const code = new FormData(form).get('code').trim();
+
+    submitBtn.disabled = true;
+    submitBtn.textContent = 'Проверка...';
+
+    try {
+        await redeemMagicLink({ email: magicEmail, code: code }, 'magic-form');
+    } finally {
+        submitBtn.disabled = false;
+        submitBtn.textContent = 'Войти';
+    }
+}
+
+async function redeemMagicLink(body, formId) {
+    try {
+        const response = await fetch('/api/v1/login/magic/verify', {
+            method: 'POST',
+            credentials: 'same-origin',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(body)
+        });
+        const data = await response.json();
+
+        if (response.ok) {
+            finishLogin(data, formId);
+        } else {
+            showMessage(formId, data.error || 'Ссылка недействительна');
+        }
+    } catch (error) {
+        showMessage(formId, 'Ошибка соединения с сервером');
+    }
+}
+
+// ========== ВХОД ЧЕРЕЗ ВНЕШНИХ ПРОВАЙДЕРОВ (OIDC) ==========
+const oidcErrorMessages = {
+    access_denied: 'Вход отменён',
+    invalid_state: 'Сеанс входа устарел, попробуйте ещё раз',
+    email_not_verified: 'Провайдер не подтвердил ваш email',
+    account_not_verified: 'Аккаунт с этим email не подтверждён. Войдите по паролю и подтвердите email',
+    provider_unavailable: 'Сервис входа сейчас недоступен',
+    provider_error: 'Не удалось войти через внешний сервис'
+};
+
+async function loadOIDCProviders() {
+    const container = document.getElementById('oidc-providers');
+    if (!container) return;
+
+    try {
+        const response = await fetch('/api/v1/oidc/providers');
+        if (!response.ok) return;
+        const data = await response.json();
+
+        data.providers.forEach(provider => {
+            const link = document.createElement('a');
+            link.className = 'btn btn-oidc';
+            link.href = provider.loginUrl;
+            link.textContent = 'Войти через ' + provider.displayName;
+            container.appendChild(link);
+        });
+    } catch (error) {
+        console.log('❌ Не удалось загрузить провайдеров входа:', error);
+    }
+}
+
+async function exchangeOIDCLogin(token) {
+    try {
+        const response = await fetch('/api/v1/oidc/exchange', {
+            method: 'POST',
+            credentials: 'same-origin',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ token: token })
+        });
+        const data = await response.json();
+
+        if (response.ok) {
+            finishLogin(data, 'login-form');
+        } else {
+            showMessage('login-form', data.error || oidcErrorMessages.provider_error);
+        }
+    } catch (error) {
+        showMessage('login-form', 'Ошибка соединения с сервером');
+    }
+}
+
+// ========== ВТОРОЙ ШАГ ВХОДА (2FA) ==========
+async function handleTwoFactor(e) {
+    e.preventDefault();
+
+    const form = e.target;
+    const submitBtn = form.querySelector('button[type="submit"]');
+    const code = new FormData(form).get('code').trim();
+
+    submitBtn.disabled = true;
+    submitBtn.textContent = 'Проверка...';
+
+    try {
+        const response = await fetch('/api/v1/login/2fa', {
+            method: 'POST',
+            credentials: 'same-origin',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ challengeToken: challengeToken, code: code })
+        });
+        const data = await response.json();
+
+        if (response.ok) {
+            localStorage.setItem('user', JSON.stringify(data.user));
+            window.location.href = '/tasks';
+        } else {
+            showMessage('two-factor-form', data.error || 'Неверный код');
+        }
+    } catch (error) {
+        showMessage('two-factor-form', 'Ошибка соединения с сервером');
+    } finally {
+        submitBtn.disabled = false;
+        submitBtn.textContent = 'Подтвердить';
+    }
+}
+
 // ========== ОБРАБОТЧИК РЕГИСТРАЦИИ ==========
 async function handleRegister(e) {
     e.preventDefault();
@@ -311,8 +540,9 @@ async function handleRegister(e) {
         return;
     }
 
-    if (!password || password.length < 6) {
-        showMessage('register-form', 'Пароль должен быть минимум 6 символов');
+    const passwordInput = document.getElementById('register-password');
+    if (!password || password.length < passwordInput.minLength) {
+        showMessage('register-form', `Пароль должен быть минимум ${passwordInput.minLength} символов`);
         return;
     }
 
@@ -348,7 +578,7 @@ async function handleRegister(e) {
                 showVerificationModal(email);
             }, 1000);
         } else {
-            showMessage('register-form', result.error || 'Ошибка сервера');
+            showMessage('register-form', passwordErrorMessage(result, 'Ошибка сервера'));
         }
     } catch (error) {
         showMessage('register-form', 'Ошибка соединения с сервером');
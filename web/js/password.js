@@ -0,0 +1,117 @@
+// web/js/password.js
+
+// ========== ГЛОБАЛЬНЫЕ ПЕРЕМЕННЫЕ ==========
+let resetEmail = '';
+let resetToken = '';
+
+// ========== ИНИЦИАЛИЗАЦИЯ ПРИ ЗАГРУЗКЕ ==========
+document.addEventListener('DOMContentLoaded', () => {
+    resetToken = document.getElementById('reset-container').dataset.token || '';
+
+    document.getElementById('forgot-form').addEventListener('submit', handleForgot);
+    document.getElementById('reset-form').addEventListener('submit', handleReset);
+    applyPasswordPolicy('reset-password');
+
+    // Пришли по ссылке из письма - код не нужен, сразу новый пароль
+    if (resetToken) {
+        document.getElementById('reset-code-group').style.display = 'none';
+        showStep('reset-form');
+    }
+});
+
+function showStep(formId) {
+    document.querySelectorAll('.form').forEach(form => form.classList.remove('active'));
+    document.getElementById(formId).classList.add('active');
+}
+
+function showMessage(formId, message, isError = true) {
+    const form = document.getElementById(formId);
+    let msgDiv = form.querySelector('.error-message, .success-message');
+
+    if (!msgDiv) {
+        msgDiv = document.createElement('div');
+        form.insertBefore(msgDiv, form.firstChild);
+    }
+    msgDiv.className = isError ? 'error-message' : 'success-message';
+    msgDiv.textContent = message;
+    msgDiv.classList.add('show');
+}
+
+// ========== ШАГ 1: ЗАПРОС КОДА ==========
+async function handleForgot(e) {
+    e.preventDefault();
+
+    const form = e.target;
+    const submitBtn = form.querySelector('button[type="submit"]');
+    resetEmail = new FormData(form).get('email');
+
+    submitBtn.disabled = true;
+    submitBtn.textContent = 'Отправка...';
+
+    try {
+        const response = await fetch('/api/v1/password/forgot', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ email: resetEmail })
+        });
+        const data = await response.json();
+
+        if (response.ok) {
+            showStep('reset-form');
+            showMessage('reset-form', 'Если такой email зарегистрирован, мы отправили на него код', false);
+        } else {
+            showMessage('forgot-form', data.error || 'Ошибка сервера');
+        }
+    } catch (error) {
+        showMessage('forgot-form', 'Ошибка соединения с сервером');
+    } finally {
+        submitBtn.disabled = false;
+        submitBtn.textContent = 'Отправить код';
+    }
+}
+
+// ========== ШАГ 2: НОВЫЙ ПАРОЛЬ ==========
+async function handleReset(e) {
+    e.preventDefault();
+
+    const form = e.target;
+    const submitBtn = form.querySelector('button[type="submit"]');
+    const formData = new FormData(form);
+    const password = formData.get('password');
+
+    if (password !== formData.get('confirm-password')) {
+        showMessage('reset-form', 'Пароли не совпадают!');
+        return;
+    }
+
+    const body = resetToken
+        ? { token: resetToken, password: password }
+        : { email: resetEmail, code: formData.get('code'), password: password };
+
+    submitBtn.disabled = true;
+    submitBtn.textContent = 'Сохранение...';
+
+    try {
+        const response = await fetch('/api/v1/password/reset', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(body)
+        });
+        const data = await response.json();
+
+        if (response.ok) {
+            localStorage.removeItem('user');
+            showMessage('reset-form', 'Пароль изменён. Сейчас вы перейдёте на страницу входа.', false);
+            setTimeout(() => {
+                window.location.href = data.redirect || '/login';
+            }, 1500);
+        } else {
+            showMessage('reset-form', passwordErrorMessage(data, 'Ошибка сервера'));
+        }
+    } catch (error) {
+        showMessage('reset-form', 'Ошибка соединения с сервером');
+    } finally {
+        submitBtn.disabled = false;
+        submitBtn.textContent = 'Сменить пароль';
+    }
+}
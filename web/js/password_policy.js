@@ -0,0 +1,41 @@
+// Требования к паролю: подсказки в формах и разбор отказов сервера (reasons)
+
+const passwordReasonMessages = {
+    too_short: limit => `Пароль слишком короткий: минимум ${limit} символов`,
+    too_long: limit => `Пароль слишком длинный: максимум ${limit} символов`,
+    common: () => 'Этот пароль слишком распространён, придумайте другой',
+    contains_email: () => 'Пароль не должен содержать ваш email',
+    too_weak: () => 'Пароль легко подобрать. Добавьте слов или символов, избегайте имён, дат и рядов клавиатуры'
+};
+
+// passwordErrorMessage текст ошибки для ответа сервера: причины по-русски или общий error
+function passwordErrorMessage(result, fallback) {
+    if (!result || !Array.isArray(result.reasons) || result.reasons.length === 0) {
+        return (result && result.error) || fallback;
+    }
+    return result.reasons
+        .map(reason => {
+            const message = passwordReasonMessages[reason.code];
+            return message ? message(reason.limit) : reason.message;
+        })
+        .join('. ');
+}
+
+// applyPasswordPolicy подставляет в поля ввода актуальные ограничения политики
+async function applyPasswordPolicy(...inputIds) {
+    try {
+        const response = await fetch('/api/v1/password/policy');
+        if (!response.ok) return;
+        const policy = await response.json();
+
+        inputIds.forEach(id => {
+            const input = document.getElementById(id);
+            if (!input) return;
+            input.minLength = policy.minLength;
+            if (policy.maxLength > 0) input.maxLength = policy.maxLength;
+            input.placeholder = `Минимум ${policy.minLength} символов`;
+        });
+    } catch (error) {
+        // Не критично: сервер всё равно проверит пароль
+    }
+}
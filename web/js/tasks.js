@@ -95,22 +95,74 @@ function showNotification(message, type = 'info') {
 
 // ========== РАБОТА С API ==========
 
+// CSRF-токен из <meta name="csrf-token">: сервер требует его в заголовке
+// у изменяющих запросов, авторизованных cookie
+function csrfHeaders() {
+    const meta = document.querySelector('meta[name="csrf-token"]');
+    return meta && meta.content ? { 'X-CSRF-Token': meta.content } : {};
+}
+
+// Access-токен живёт недолго: при 401 один раз пробуем обновить его
+// по refresh-cookie и повторить запрос.
+// Обновление идёт по одному: refresh-токен, предъявленный дважды, сервер
+// считает украденным. Параллельные 401 ждут общий запрос, а соседние
+// вкладки - блокировку и идут уже с новой cookie.
+let refreshInFlight = null;
+
+function refreshSession() {
+    if (!refreshInFlight) {
+        refreshInFlight = withRefreshLock(requestRefresh).finally(() => {
+            refreshInFlight = null;
+        });
+    }
+    return refreshInFlight;
+}
+
+function withRefreshLock(fn) {
+    if (navigator.locks) {
+        return navigator.locks.request('taskflow-session-refresh', fn);
+    }
+    return fn();
+}
+
+async function requestRefresh() {
+    try {
+        // Новые токены сервер кладёт в cookie, в ответе их нет
+        const response = await fetch('/api/v1/token/refresh', {
+            method: 'POST',
+            credentials: 'same-origin',
+            headers: csrfHeaders()
+        });
+        return response.ok;
+    } catch (error) {
+        return false;
+    }
+}
+
+async function apiFetch(url, options = {}, retry = true) {
+    // Авторизация - HttpOnly cookie, скрипт токенов не видит
+    const headers = { ...csrfHeaders(), ...(options.headers || {}) };
+    const response = await fetch(url, { ...options, headers, credentials: 'same-origin' });
+
+    if (response.status === 401 && retry) {
+        if (await refreshSession()) {
+            return apiFetch(url, options, false);
+        }
+        localStorage.removeItem('user');
+        window.location.href = '/login';
+    }
+    return response;
+}
+
 // TODO: 1. Загрузить задачи с сервера
 async function fetchTasks() {
     try {
-        const token = localStorage.getItem('token');
-        const response = await fetch('/api/v1/tasks', {
+        const response = await apiFetch('/api/v1/tasks', {
             method: 'GET',
-            headers: {
-                'Content-Type': 'application/json',
-                'Authorization': token }
+            headers: { 'Content-Type': 'application/json' }
         });
 
         if (!response.ok) {
-            if (response.status === 401) {
-                window.location.href = '/login';
-                return;
-            }
             throw new Error('Failed to fetch tasks');
         }
 
@@ -129,13 +181,9 @@ async function fetchTasks() {
 // TODO: 2. Создать задачу
 async function createTask(taskData) {
     try {
-        const token = localStorage.getItem('token');
-        const response = await fetch('/api/v1/tasks', {
+        const response = await apiFetch('/api/v1/tasks', {
             method: 'POST',
-            headers: {
-                'Content-Type': 'application/json',
-                'Authorization': token
-            },
+            headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify(taskData)
         });
 
@@ -144,7 +192,7 @@ async function createTask(taskData) {
         }
 
         const newTask = await response.json();
-        tasks.unshift(newTask);
+        upsertTask(newTask);
         renderTasks();
         showNotification('Задача создана', false);
     } catch (error) {
@@ -156,13 +204,9 @@ async function createTask(taskData) {
 async function updateTask(taskId, updates) {
     // updates: { title?, description? }
     try {
-        const token = localStorage.getItem('token');
-        const response = await fetch(`/api/v1/tasks/${taskId}`, {
+        const response = await apiFetch(`/api/v1/tasks/${taskId}`, {
             method: 'PATCH',
-            headers: {
-                'Content-Type': 'application/json',
-                'Authorization': token
-            },
+            headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify(updates)
         });
 
@@ -186,12 +230,8 @@ async function updateTask(taskId, updates) {
 async function toggleTask(taskId) {
     // Пример:
     try {
-        const token = localStorage.getItem('token');
-        const response = await fetch(`/api/v1/tasks/${taskId}/toggle`, {
-            method: 'PUT',
-            headers: {
-                'Authorization': token
-            }
+        const response = await apiFetch(`/api/v1/tasks/${taskId}/toggle`, {
+            method: 'PUT'
         });
 
         if (!response.ok) {
@@ -213,12 +253,8 @@ async function toggleTask(taskId) {
 // TODO: 5. Удалить задачу
 async function deleteTask(taskId) {
     try {
-        const token = localStorage.getItem('token');
-        const response = await fetch(`/api/v1/tasks/${taskId}`, {
-            method: 'DELETE',
-            headers: {
-                'Authorization': token
-            }
+        const response = await apiFetch(`/api/v1/tasks/${taskId}`, {
+            method: 'DELETE'
         });
 
         if (!response.ok) {
@@ -233,6 +269,54 @@ async function deleteTask(taskId) {
     }
 }
 
+// ========== ОБНОВЛЕНИЯ В РЕАЛЬНОМ ВРЕМЕНИ (SSE) ==========
+
+// Добавить или заменить задачу по id (события приходят и во вкладку-автора)
+function upsertTask(task) {
+    const index = tasks.findIndex(t => t.id === task.id);
+    if (index !== -1) {
+        tasks[index] = { ...tasks[index], ...task };
+    } else {
+        tasks.unshift(task);
+    }
+}
+
+// Подписка на /api/v1/events. EventSource сам переподключается
+// и передаёт Last-Event-ID, так что пропущенные события сервер дошлёт.
+function subscribeToEvents() {
+    if (!window.EventSource) return;
+
+    const source = new EventSource('/api/v1/events');
+
+    source.addEventListener('task.created', (e) => {
+        upsertTask(JSON.parse(e.data).data);
+        renderTasks();
+    });
+
+    source.addEventListener('task.updated', (e) => {
+        upsertTask(JSON.parse(e.data).data);
+        renderTasks();
+    });
+
+    source.addEventListener('task.deleted', (e) => {
+        const { id } = JSON.parse(e.data).data;
+        tasks = tasks.filter(t => t.id !== id);
+        renderTasks();
+    });
+
+    // Сервер не смог дослать пропущенное - перечитываем список целиком
+    source.addEventListener('reset', () => fetchTasks());
+
+    // На 401 (истёк access-токен) EventSource не переподключается сам
+    source.onerror = async () => {
+        if (source.readyState === EventSource.CLOSED) {
+            if (await refreshSession()) {
+                subscribeToEvents();
+            }
+        }
+    };
+}
+
 // ========== РЕНДЕРИНГ ЗАДАЧ ==========
 
 function renderTasks() {
@@ -355,12 +439,11 @@ function cancelEdit(card) {
 document.addEventListener('DOMContentLoaded', () => {
     initNotifications()
 
-    const token = localStorage.getItem('token');
-    if (!token) {
-        showNotification("Not authorized", "error")
-        window.location.href = '/login';
-    }
+    // Токен раньше хранился здесь - убираем остатки старых версий
+    localStorage.removeItem('token');
+
     fetchTasks();
+    subscribeToEvents();
 
     // Форма создания задачи
     const showFormBtn = document.getElementById('showCreateFormBtn');
@@ -438,10 +521,18 @@ document.addEventListener('DOMContentLoaded', () => {
             modal.remove();
         });
 
-        document.getElementById('confirmLogout').addEventListener('click', () => {
-            localStorage.removeItem('token');
+        document.getElementById('confirmLogout').addEventListener('click', async () => {
+            // cookie HttpOnly - удалить их и завершить сессию может только сервер
+            try {
+                await fetch('/api/v1/logout', {
+                    method: 'POST',
+                    credentials: 'same-origin',
+                    headers: csrfHeaders()
+                });
+            } catch (error) {
+                console.log('❌ Ошибка выхода:', error);
+            }
             localStorage.removeItem('user');
-            document.cookie = 'token=; path=/; expires=Thu, 01 Jan 1970 00:00:00 GMT';
             window.location.href = '/login';
         });
 